@@ -2,7 +2,6 @@ package gorm_test
 
 import (
 	"fmt"
-	"os"
 	"reflect"
 	"sort"
 	"testing"
@@ -843,10 +842,6 @@ func TestForeignKey(t *testing.T) {
 }
 
 func testForeignKey(t *testing.T, source interface{}, sourceFieldName string, target interface{}, targetFieldName string) {
-	if dialect := os.Getenv("GORM_DIALECT"); dialect == "" || dialect == "sqlite" {
-		// sqlite does not support ADD CONSTRAINT in ALTER TABLE
-		return
-	}
 	targetScope := DB.NewScope(target)
 	targetTableName := targetScope.TableName()
 	modelScope := DB.NewScope(source)
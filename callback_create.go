@@ -1,23 +1,38 @@
 package gorm
 
 import (
+	"database/sql"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
 // Define callbacks for creating
 func init() {
 	DefaultCallback.Create().Register("gorm:begin_transaction", beginTransactionCallback)
-	DefaultCallback.Create().Register("gorm:before_create", beforeCreateCallback)
-	DefaultCallback.Create().Register("gorm:save_before_associations", saveBeforeAssociationsCallback)
-	DefaultCallback.Create().Register("gorm:update_time_stamp", updateTimeStampForCreateCallback)
+	DefaultCallback.Create().Register("gorm:before_create", forEachElemCallback(beforeCreateCallback))
+	DefaultCallback.Create().Register("gorm:save_before_associations", forEachElemCallback(saveBeforeAssociationsCallback))
+	DefaultCallback.Create().Register("gorm:update_time_stamp", forEachElemCallback(updateTimeStampForCreateCallback))
 	DefaultCallback.Create().Register("gorm:create", createCallback)
-	DefaultCallback.Create().Register("gorm:force_reload_after_create", forceReloadAfterCreateCallback)
-	DefaultCallback.Create().Register("gorm:save_after_associations", saveAfterAssociationsCallback)
-	DefaultCallback.Create().Register("gorm:after_create", afterCreateCallback)
+	DefaultCallback.Create().Register("gorm:force_reload_after_create", forEachElemCallback(forceReloadAfterCreateCallback))
+	DefaultCallback.Create().Register("gorm:save_after_associations", forEachElemCallback(saveAfterAssociationsCallback))
+	DefaultCallback.Create().Register("gorm:after_create", forEachElemCallback(afterCreateCallback))
 	DefaultCallback.Create().Register("gorm:commit_or_rollback_transaction", commitOrRollbackTransactionCallback)
 }
 
+// forEachElemCallback wraps a callback written for a single struct, when creating a slice or an array, it will be called for every element
+func forEachElemCallback(callback func(scope *Scope)) func(scope *Scope) {
+	return func(scope *Scope) {
+		if elemScopes, ok := scope.elemScopes(); ok {
+			for _, elemScope := range elemScopes {
+				callback(elemScope)
+			}
+		} else {
+			callback(scope)
+		}
+	}
+}
+
 // beforeCreateCallback will invoke `BeforeSave`, `BeforeCreate` method before creating
 func beforeCreateCallback(scope *Scope) {
 	if !scope.HasError() {
@@ -28,20 +43,20 @@ func beforeCreateCallback(scope *Scope) {
 	}
 }
 
-// updateTimeStampForCreateCallback will set `CreatedAt`, `UpdatedAt` when creating
+// updateTimeStampForCreateCallback will set blank fields tagged with `autoCreateTime` or `autoUpdateTime`, e.g. `CreatedAt`, `UpdatedAt` when creating
 func updateTimeStampForCreateCallback(scope *Scope) {
 	if !scope.HasError() {
-		now := NowFunc()
+		now := scope.db.now()
 
-		if createdAtField, ok := scope.FieldByName("CreatedAt"); ok {
-			if createdAtField.IsBlank {
-				createdAtField.Set(now)
+		for _, field := range scope.Fields() {
+			if !field.IsBlank {
+				continue
 			}
-		}
 
-		if updatedAtField, ok := scope.FieldByName("UpdatedAt"); ok {
-			if updatedAtField.IsBlank {
-				updatedAtField.Set(now)
+			if unit, ok := field.autoTime("AUTOCREATETIME"); ok {
+				field.Set(field.autoTimeValue(now, unit))
+			} else if unit, ok := field.autoTime("AUTOUPDATETIME"); ok {
+				field.Set(field.autoTimeValue(now, unit))
 			}
 		}
 	}
@@ -50,38 +65,27 @@ func updateTimeStampForCreateCallback(scope *Scope) {
 // createCallback the callback used to insert data into database
 func createCallback(scope *Scope) {
 	if !scope.HasError() {
+		if elemScopes, ok := scope.elemScopes(); ok {
+			createInBatches(scope, elemScopes)
+			return
+		}
+
 		defer scope.trace(NowFunc())
 
 		var (
-			columns, placeholders        []string
-			blankColumnsWithDefaultValue []string
+			columns, fields = scope.insertColumnsAndFields()
+			placeholders    []string
 		)
 
-		for _, field := range scope.Fields() {
-			if scope.changeableField(field) {
-				if field.IsNormal {
-					if field.IsBlank && field.HasDefaultValue {
-						blankColumnsWithDefaultValue = append(blankColumnsWithDefaultValue, scope.Quote(field.DBName))
-						scope.InstanceSet("gorm:blank_columns_with_default_value", blankColumnsWithDefaultValue)
-					} else if !field.IsPrimaryKey || !field.IsBlank {
-						columns = append(columns, scope.Quote(field.DBName))
-						placeholders = append(placeholders, scope.AddToVars(field.Field.Interface()))
-					}
-				} else if field.Relationship != nil && field.Relationship.Kind == "belongs_to" {
-					for _, foreignKey := range field.Relationship.ForeignDBNames {
-						if foreignField, ok := scope.FieldByName(foreignKey); ok && !scope.changeableField(foreignField) {
-							columns = append(columns, scope.Quote(foreignField.DBName))
-							placeholders = append(placeholders, scope.AddToVars(foreignField.Field.Interface()))
-						}
-					}
-				}
-			}
+		for _, field := range fields {
+			placeholders = append(placeholders, scope.addColumnToVars(field.DBName, field.Field.Interface()))
 		}
 
 		var (
 			returningColumn = "*"
 			quotedTableName = scope.QuotedTableName()
 			primaryField    = scope.PrimaryField()
+			upsert          = scope.Search.onConflict != nil
 			extraOption     string
 		)
 
@@ -93,35 +97,39 @@ func createCallback(scope *Scope) {
 			returningColumn = scope.Quote(primaryField.DBName)
 		}
 
+		lastInsertIDOutputInterstitial := lastInsertIDOutputInterstitial(scope.Dialect(), quotedTableName, returningColumn, columns)
 		lastInsertIDReturningSuffix := scope.Dialect().LastInsertIDReturningSuffix(quotedTableName, returningColumn)
 
 		if len(columns) == 0 {
 			scope.Raw(fmt.Sprintf(
-				"INSERT INTO %v %v%v%v",
+				"INSERT INTO %v%v %v%v%v",
 				quotedTableName,
+				addExtraSpaceIfExist(lastInsertIDOutputInterstitial),
 				scope.Dialect().DefaultValueStr(),
 				addExtraSpaceIfExist(extraOption),
 				addExtraSpaceIfExist(lastInsertIDReturningSuffix),
 			))
 		} else {
 			scope.Raw(fmt.Sprintf(
-				"INSERT INTO %v (%v) VALUES (%v)%v%v",
-				scope.QuotedTableName(),
-				strings.Join(columns, ","),
-				strings.Join(placeholders, ","),
+				"%v%v%v",
+				scope.insertSQL(columns, []string{fmt.Sprintf("(%v)", strings.Join(placeholders, ","))}, lastInsertIDOutputInterstitial),
 				addExtraSpaceIfExist(extraOption),
 				addExtraSpaceIfExist(lastInsertIDReturningSuffix),
 			))
 		}
 
+		if scope.HasError() || scope.dryRun() {
+			return
+		}
+
 		// execute create sql
-		if lastInsertIDReturningSuffix == "" || primaryField == nil {
-			if result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+		if (lastInsertIDReturningSuffix == "" && lastInsertIDOutputInterstitial == "") || primaryField == nil {
+			if result, err := execContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
 				// set rows affected count
 				scope.db.RowsAffected, _ = result.RowsAffected()
 
-				// set primary value to primary field
-				if primaryField != nil && primaryField.IsBlank {
+				// set primary value to primary field, the last insert id is meaningless if the record was updated when upserting
+				if primaryField != nil && primaryField.IsBlank && !upsert {
 					if primaryValue, err := result.LastInsertId(); scope.Err(err) == nil {
 						scope.Err(primaryField.Set(primaryValue))
 					}
@@ -129,7 +137,10 @@ func createCallback(scope *Scope) {
 			}
 		} else {
 			if primaryField.Field.CanAddr() {
-				if err := scope.SQLDB().QueryRow(scope.SQL, scope.SQLVars...).Scan(primaryField.Field.Addr().Interface()); scope.Err(err) == nil {
+				err := queryRowContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...).Scan(primaryField.Field.Addr().Interface())
+				if err == sql.ErrNoRows && upsert {
+					// conflicted record is skipped
+				} else if scope.Err(err) == nil {
 					primaryField.IsBlank = false
 					scope.db.RowsAffected = 1
 				}
@@ -140,6 +151,288 @@ func createCallback(scope *Scope) {
 	}
 }
 
+// createInBatches insert elements of a slice or an array with multi-row INSERT statements, consecutive elements
+// having the same columns are inserted together, split by `gorm:batch_size` or the dialect's bind variables limit
+func createInBatches(scope *Scope, elemScopes []*Scope) {
+	var (
+		batchColumns []string
+		batch        []*Scope
+		batchFields  [][]*Field
+	)
+
+	flush := func() {
+		if len(batch) > 0 {
+			insertBatch(scope, batch, batchColumns, batchFields)
+			batch, batchFields = nil, nil
+		}
+	}
+
+	for _, elemScope := range elemScopes {
+		columns, fields := elemScope.insertColumnsAndFields()
+		if strings.Join(columns, ",") != strings.Join(batchColumns, ",") || len(batch) >= scope.batchSize(len(columns)) {
+			flush()
+		}
+		batchColumns = columns
+		batch = append(batch, elemScope)
+		batchFields = append(batchFields, fields)
+	}
+	flush()
+}
+
+func insertBatch(scope *Scope, elemScopes []*Scope, columns []string, fields [][]*Field) {
+	if scope.HasError() {
+		return
+	}
+
+	if len(columns) == 0 {
+		// rows without any column can't be inserted together
+		for _, elemScope := range elemScopes {
+			createCallback(elemScope)
+		}
+		return
+	}
+
+	defer scope.trace(NowFunc())
+	scope.SQLVars, scope.redactedVars = nil, nil
+
+	var (
+		returningColumn = "*"
+		quotedTableName = scope.QuotedTableName()
+		primaryField    = elemScopes[0].PrimaryField()
+		rows            []string
+		extraOption     string
+	)
+
+	for _, rowFields := range fields {
+		var placeholders []string
+		for _, field := range rowFields {
+			placeholders = append(placeholders, scope.addColumnToVars(field.DBName, field.Field.Interface()))
+		}
+		rows = append(rows, fmt.Sprintf("(%v)", strings.Join(placeholders, ",")))
+	}
+
+	if str, ok := scope.Get("gorm:insert_option"); ok {
+		extraOption = fmt.Sprint(str)
+	}
+
+	if primaryField != nil {
+		returningColumn = scope.Quote(primaryField.DBName)
+	}
+
+	lastInsertIDOutputInterstitial := lastInsertIDOutputInterstitial(scope.Dialect(), quotedTableName, returningColumn, columns)
+	lastInsertIDReturningSuffix := scope.Dialect().LastInsertIDReturningSuffix(quotedTableName, returningColumn)
+
+	scope.Raw(fmt.Sprintf(
+		"%v%v%v",
+		scope.insertSQL(columns, rows, lastInsertIDOutputInterstitial),
+		addExtraSpaceIfExist(extraOption),
+		addExtraSpaceIfExist(lastInsertIDReturningSuffix),
+	))
+
+	if scope.HasError() || scope.dryRun() {
+		return
+	}
+
+	// elements in the same batch share the same columns, so primary keys are all blank or all set
+	if primaryField == nil || !primaryField.IsBlank {
+		if result, err := execContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+			rowsAffected, _ := result.RowsAffected()
+			scope.db.RowsAffected += rowsAffected
+		}
+	} else if lastInsertIDReturningSuffix == "" && lastInsertIDOutputInterstitial == "" {
+		if result, err := execContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+			rowsAffected, _ := result.RowsAffected()
+			scope.db.RowsAffected += rowsAffected
+
+			// set primary values with the last insert id and row offset, which can't be used if some rows were updated when upserting
+			if scope.Search.onConflict == nil {
+				if lastInsertID, err := result.LastInsertId(); scope.Err(err) == nil {
+					firstInsertID := lastInsertID
+					if dialect, ok := scope.Dialect().(lastInsertIDOfLastRowDialect); ok && dialect.lastInsertIDOfLastRow() {
+						firstInsertID = lastInsertID - int64(len(elemScopes)-1)
+					}
+
+					for idx, elemScope := range elemScopes {
+						scope.Err(elemScope.PrimaryField().Set(firstInsertID + int64(idx)))
+					}
+				}
+			}
+		}
+	} else {
+		if rows, err := queryContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+			defer rows.Close()
+
+			var primaryValues []reflect.Value
+			for rows.Next() {
+				primaryValue := reflect.New(primaryField.Field.Type())
+				if scope.Err(rows.Scan(primaryValue.Interface())) == nil {
+					primaryValues = append(primaryValues, primaryValue.Elem())
+				}
+			}
+
+			if scope.Err(rows.Err()) == nil {
+				scope.db.RowsAffected += int64(len(primaryValues))
+
+				// skipped rows are not returned when upserting, then returned values can't be matched with elements
+				if len(primaryValues) == len(elemScopes) {
+					for idx, elemScope := range elemScopes {
+						scope.Err(elemScope.PrimaryField().Set(primaryValues[idx]))
+					}
+				}
+			}
+		}
+	}
+}
+
+// insertSQL build the INSERT statement of rows, conflicts will be resolved by the dialect if `OnConflict` is used
+func (scope *Scope) insertSQL(columns []string, rows []string, output string) string {
+	if onConflict, ok := scope.onConflict(columns); ok {
+		sql, err := upsertSQL(scope.Dialect(), scope.QuotedTableName(), columns, rows, output, onConflict)
+		scope.Err(err)
+		return sql
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %v (%v)%v VALUES %v",
+		scope.QuotedTableName(),
+		strings.Join(columns, ","),
+		addExtraSpaceIfExist(output),
+		strings.Join(rows, ","),
+	)
+}
+
+// conflictTarget return the default conflict target, inserted primary keys are preferred, otherwise columns of the first
+// unique column or unique index that are all inserted, primary keys are used if none of them are inserted
+func (scope *Scope) conflictTarget(columns []string) (target []string) {
+	inserted := map[string]bool{}
+	for _, column := range columns {
+		inserted[column] = true
+	}
+
+	for _, field := range scope.PrimaryFields() {
+		if inserted[scope.Quote(field.DBName)] {
+			target = append(target, scope.Quote(field.DBName))
+		}
+	}
+	if len(target) > 0 {
+		return target
+	}
+
+	for _, field := range scope.Fields() {
+		if _, ok := field.TagSettings["UNIQUE"]; ok && inserted[scope.Quote(field.DBName)] {
+			return []string{scope.Quote(field.DBName)}
+		}
+	}
+
+	for _, index := range scope.modelIndexes() {
+		if !index.Unique || index.Where != "" {
+			continue
+		}
+
+		target = nil
+		for _, column := range index.Columns {
+			if inserted[scope.Quote(column)] {
+				target = append(target, scope.Quote(column))
+			}
+		}
+		if len(target) == len(index.Columns) {
+			return target
+		}
+	}
+
+	target = nil
+	for _, field := range scope.PrimaryFields() {
+		target = append(target, scope.Quote(field.DBName))
+	}
+	return target
+}
+
+// onConflict return current search's OnConflict with quoted column names, conflict target defaults to conflictTarget,
+// columns to update are resolved from inserted columns for `UpdateAll`
+func (scope *Scope) onConflict(columns []string) (onConflict OnConflict, ok bool) {
+	if scope.Search.onConflict == nil {
+		return onConflict, false
+	}
+
+	quoteColumn := func(name string) string {
+		if field, ok := scope.FieldByName(name); ok {
+			return scope.Quote(field.DBName)
+		}
+		return scope.Quote(name)
+	}
+
+	onConflict = OnConflict{DoNothing: scope.Search.onConflict.DoNothing, UpdateAll: scope.Search.onConflict.UpdateAll}
+	for _, column := range scope.Search.onConflict.Columns {
+		onConflict.Columns = append(onConflict.Columns, quoteColumn(column))
+	}
+
+	keptColumns := map[string]bool{}
+	for _, field := range scope.Fields() {
+		if !field.writable("update") {
+			keptColumns[scope.Quote(field.DBName)] = true
+		}
+	}
+	for _, field := range scope.PrimaryFields() {
+		keptColumns[scope.Quote(field.DBName)] = true
+	}
+	if len(onConflict.Columns) == 0 {
+		onConflict.Columns = scope.conflictTarget(columns)
+	}
+	for _, column := range onConflict.Columns {
+		keptColumns[column] = true
+	}
+	for _, field := range scope.Fields() {
+		if _, ok := field.autoTime("AUTOCREATETIME"); ok {
+			keptColumns[scope.Quote(field.DBName)] = true
+		}
+	}
+
+	for _, column := range scope.Search.onConflict.DoUpdates {
+		onConflict.DoUpdates = append(onConflict.DoUpdates, quoteColumn(column))
+	}
+
+	if onConflict.UpdateAll {
+		for _, column := range columns {
+			if !keptColumns[column] {
+				onConflict.DoUpdates = append(onConflict.DoUpdates, column)
+			}
+		}
+	}
+
+	if len(onConflict.DoUpdates) == 0 {
+		onConflict.DoNothing = true
+	}
+	return onConflict, true
+}
+
+// insertColumnsAndFields return quoted columns and their fields to insert, blank columns that have default value
+// are saved to `gorm:blank_columns_with_default_value` to be reloaded after creating
+func (scope *Scope) insertColumnsAndFields() (columns []string, fields []*Field) {
+	var blankColumnsWithDefaultValue []string
+
+	for _, field := range scope.Fields() {
+		if scope.changeableField(field) && field.writable("create") {
+			if field.IsNormal {
+				if field.IsBlank && field.HasDefaultValue {
+					blankColumnsWithDefaultValue = append(blankColumnsWithDefaultValue, scope.Quote(field.DBName))
+					scope.InstanceSet("gorm:blank_columns_with_default_value", blankColumnsWithDefaultValue)
+				} else if !field.IsPrimaryKey || !field.IsBlank {
+					columns = append(columns, scope.Quote(field.DBName))
+					fields = append(fields, field)
+				}
+			} else if field.Relationship != nil && field.Relationship.Kind == "belongs_to" {
+				for _, foreignKey := range field.Relationship.ForeignDBNames {
+					if foreignField, ok := scope.FieldByName(foreignKey); ok && !scope.changeableField(foreignField) && foreignField.writable("create") {
+						columns = append(columns, scope.Quote(foreignField.DBName))
+						fields = append(fields, foreignField)
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
 // forceReloadAfterCreateCallback will reload columns that having default value, and set it back to current object
 func forceReloadAfterCreateCallback(scope *Scope) {
 	if blankColumnsWithDefaultValue, ok := scope.InstanceGet("gorm:blank_columns_with_default_value"); ok {
@@ -25,7 +25,7 @@ func beforeDeleteCallback(scope *Scope) {
 	}
 }
 
-// deleteCallback used to delete data from database or set deleted_at to current time (when using with soft delete)
+// deleteCallback used to delete data from database or set the soft delete field, e.g. deleted_at to current time (when using with soft delete)
 func deleteCallback(scope *Scope) {
 	if !scope.HasError() {
 		var extraOption string
@@ -33,14 +33,20 @@ func deleteCallback(scope *Scope) {
 			extraOption = fmt.Sprint(str)
 		}
 
-		deletedAtField, hasDeletedAtField := scope.FieldByName("DeletedAt")
+		softDeleteField, softDeleter := scope.softDeleteField()
+
+		if !scope.Search.Unscoped && softDeleteField != nil {
+			sets := fmt.Sprintf("%v=%v", scope.Quote(softDeleteField.DBName), scope.AddToVars(softDeleter.DeletedValue(scope)))
+			if deletedByField := scope.deletedByField(); deletedByField != nil {
+				if deletedBy, ok := scope.Get("gorm:deleted_by"); ok {
+					sets += fmt.Sprintf(", %v=%v", scope.Quote(deletedByField.DBName), scope.AddToVars(deletedBy))
+				}
+			}
 
-		if !scope.Search.Unscoped && hasDeletedAtField {
 			scope.Raw(fmt.Sprintf(
-				"UPDATE %v SET %v=%v%v%v",
+				"UPDATE %v SET %v%v%v",
 				scope.QuotedTableName(),
-				scope.Quote(deletedAtField.DBName),
-				scope.AddToVars(NowFunc()),
+				sets,
 				addExtraSpaceIfExist(scope.CombinedConditionSql()),
 				addExtraSpaceIfExist(extraOption),
 			)).Exec()
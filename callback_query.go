@@ -37,6 +37,12 @@ func queryCallback(scope *Scope) {
 		results = indirect(reflect.ValueOf(value))
 	}
 
+	if scope.Search.pagination != nil {
+		// cursors of previous queries shouldn't be returned if failed
+		scope.db.InstantSet("gorm:pagination_cursors", [2]string{})
+		scope.Search.pagination.prepare(scope)
+	}
+
 	if kind := results.Kind(); kind == reflect.Slice {
 		isSlice = true
 		resultType = results.Type().Elem()
@@ -59,7 +65,11 @@ func queryCallback(scope *Scope) {
 			scope.SQL += addExtraSpaceIfExist(fmt.Sprint(str))
 		}
 
-		if rows, err := scope.SQLDB().Query(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+		if scope.dryRun() {
+			return
+		}
+
+		if rows, err := queryContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
 			defer rows.Close()
 
 			columns, _ := rows.Columns()
@@ -86,6 +96,8 @@ func queryCallback(scope *Scope) {
 				scope.Err(err)
 			} else if scope.db.RowsAffected == 0 && !isSlice {
 				scope.Err(ErrRecordNotFound)
+			} else if isSlice && scope.Search.pagination != nil {
+				scope.Search.pagination.setCursors(scope, results)
 			}
 		}
 	}
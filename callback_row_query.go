@@ -1,6 +1,11 @@
 package gorm
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+)
 
 // Define callbacks for row query
 func init() {
@@ -20,11 +25,45 @@ type RowsQueryResult struct {
 func rowQueryCallback(scope *Scope) {
 	if result, ok := scope.InstanceGet("row_query_result"); ok {
 		scope.prepareQuerySQL()
+		if scope.dryRun() {
+			return
+		}
 
 		if rowResult, ok := result.(*RowQueryResult); ok {
-			rowResult.Row = scope.SQLDB().QueryRow(scope.SQL, scope.SQLVars...)
+			rowResult.Row = queryRowContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...)
 		} else if rowsResult, ok := result.(*RowsQueryResult); ok {
-			rowsResult.Rows, rowsResult.Error = scope.SQLDB().Query(scope.SQL, scope.SQLVars...)
+			rowsResult.Rows, rowsResult.Error = queryContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...)
 		}
 	}
 }
+
+// dryRunConnector connector failing with ErrDryRunModeUnsupported, used to return `*sql.Row` in dry run mode
+type dryRunConnector struct{}
+
+func (dryRunConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, ErrDryRunModeUnsupported
+}
+
+func (dryRunConnector) Driver() driver.Driver {
+	return dryRunDriver{}
+}
+
+type dryRunDriver struct{}
+
+func (dryRunDriver) Open(string) (driver.Conn, error) {
+	return nil, ErrDryRunModeUnsupported
+}
+
+var (
+	// dryRunDB is opened on the first use, as opening it starts a goroutine
+	dryRunDB     *sql.DB
+	dryRunDBOnce sync.Once
+)
+
+// dryRunRow return a `*sql.Row` whose `Scan` returns ErrDryRunModeUnsupported
+func dryRunRow(ctx context.Context) *sql.Row {
+	dryRunDBOnce.Do(func() {
+		dryRunDB = sql.OpenDB(dryRunConnector{})
+	})
+	return dryRunDB.QueryRowContext(ctx, "")
+}
@@ -3,6 +3,7 @@ package gorm
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 )
@@ -47,17 +48,25 @@ func beforeUpdateCallback(scope *Scope) {
 	}
 }
 
-// updateTimeStampForUpdateCallback will set `UpdatedAt` when updating
+// updateTimeStampForUpdateCallback will set fields tagged with `autoUpdateTime`, e.g. `UpdatedAt` when updating
 func updateTimeStampForUpdateCallback(scope *Scope) {
 	if _, ok := scope.Get("gorm:update_column"); !ok {
-		scope.SetColumn("UpdatedAt", NowFunc())
+		now := scope.db.now()
+		for _, field := range scope.Fields() {
+			if unit, ok := field.autoTime("AUTOUPDATETIME"); ok {
+				scope.SetColumn(field, field.autoTimeValue(now, unit))
+			}
+		}
 	}
 }
 
 // updateCallback the callback used to update data to database
 func updateCallback(scope *Scope) {
 	if !scope.HasError() {
-		var sqls []string
+		var (
+			sqls         []string
+			versionField = scope.versionField()
+		)
 
 		if updateAttrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
 			// Sort the column names so that the generated SQL is the same every time.
@@ -69,19 +78,27 @@ func updateCallback(scope *Scope) {
 			sort.Strings(columns)
 
 			for _, column := range columns {
+				if versionField != nil && column == versionField.DBName {
+					continue
+				}
+
+				if field, ok := scope.FieldByName(column); ok && !field.writable("update") {
+					continue
+				}
+
 				value := updateMap[column]
-				sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(column), scope.AddToVars(value)))
+				sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(column), scope.addColumnToVars(column, value)))
 			}
 		} else {
 			for _, field := range scope.Fields() {
-				if scope.changeableField(field) {
+				if scope.changeableField(field) && field.writable("update") && field != versionField {
 					if !field.IsPrimaryKey && field.IsNormal {
-						sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(field.DBName), scope.AddToVars(field.Field.Interface())))
+						sqls = append(sqls, fmt.Sprintf("%v = %v", scope.Quote(field.DBName), scope.addColumnToVars(field.DBName, field.Field.Interface())))
 					} else if relationship := field.Relationship; relationship != nil && relationship.Kind == "belongs_to" {
 						for _, foreignKey := range relationship.ForeignDBNames {
-							if foreignField, ok := scope.FieldByName(foreignKey); ok && !scope.changeableField(foreignField) {
+							if foreignField, ok := scope.FieldByName(foreignKey); ok && !scope.changeableField(foreignField) && foreignField.writable("update") {
 								sqls = append(sqls,
-									fmt.Sprintf("%v = %v", scope.Quote(foreignField.DBName), scope.AddToVars(foreignField.Field.Interface())))
+									fmt.Sprintf("%v = %v", scope.Quote(foreignField.DBName), scope.addColumnToVars(foreignField.DBName, foreignField.Field.Interface())))
 							}
 						}
 					}
@@ -95,6 +112,15 @@ func updateCallback(scope *Scope) {
 		}
 
 		if len(sqls) > 0 {
+			checkVersion := versionField != nil && !scope.PrimaryKeyZero()
+			if versionField != nil {
+				quotedVersionColumn := scope.Quote(versionField.DBName)
+				sqls = append(sqls, fmt.Sprintf("%v = %v + 1", quotedVersionColumn, quotedVersionColumn))
+				if checkVersion {
+					scope.Search.Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), quotedVersionColumn), versionField.Field.Interface())
+				}
+			}
+
 			scope.Raw(fmt.Sprintf(
 				"UPDATE %v SET %v%v%v",
 				scope.QuotedTableName(),
@@ -102,10 +128,47 @@ func updateCallback(scope *Scope) {
 				addExtraSpaceIfExist(scope.CombinedConditionSql()),
 				addExtraSpaceIfExist(extraOption),
 			)).Exec()
+
+			if checkVersion && !scope.HasError() && !scope.dryRun() {
+				if scope.db.RowsAffected == 0 {
+					scope.Err(ErrStaleObject)
+				} else {
+					scope.Err(increaseVersion(versionField))
+				}
+			}
 		}
 	}
 }
 
+// versionField return the field used for optimistic locking, which is tagged with `version` or of type `Version`,
+// a version given in updating attributes will be used as the expected version, it could be disabled with `gorm:skip_version_check`
+func (scope *Scope) versionField() *Field {
+	if skip, ok := scope.Get("gorm:skip_version_check"); ok && skip == true {
+		return nil
+	}
+
+	for _, field := range scope.Fields() {
+		if _, ok := field.TagSettings["VERSION"]; ok || field.Struct.Type == reflect.TypeOf(Version(0)) {
+			switch field.Field.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+// increaseVersion set version field to the updated version
+func increaseVersion(field *Field) error {
+	switch field.Field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field.Set(field.Field.Uint() + 1)
+	default:
+		return field.Set(field.Field.Int() + 1)
+	}
+}
+
 // afterUpdateCallback will invoke `AfterUpdate`, `AfterSave` method after updating
 func afterUpdateCallback(scope *Scope) {
 	if _, ok := scope.Get("gorm:update_column"); !ok {
@@ -0,0 +1,89 @@
+package gorm_test
+
+import (
+	"context"
+	"testing"
+)
+
+type contextKey string
+
+func TestWithContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey("key"), "value")
+	db := DB.WithContext(ctx).Where("name = ?", "context")
+
+	if db.Context() != ctx {
+		t.Errorf("Context should be kept when chaining methods")
+	}
+
+	if db.NewScope(&User{}).Context() != ctx {
+		t.Errorf("Context should be passed to scope")
+	}
+
+	if DB.Context() == nil {
+		t.Errorf("Context should default to background context")
+	}
+}
+
+func TestWithCancelledContext(t *testing.T) {
+	user := User{Name: "cancelled_context_user"}
+	DB.Save(&user)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var users []User
+	if err := DB.WithContext(ctx).Find(&users).Error; err != context.Canceled {
+		t.Errorf("Find with cancelled context should return context.Canceled, but got %v", err)
+	}
+
+	if err := DB.WithContext(ctx).Create(&User{Name: "cancelled_context_create"}).Error; err != context.Canceled {
+		t.Errorf("Create with cancelled context should return context.Canceled, but got %v", err)
+	}
+
+	if !DB.Where("name = ?", "cancelled_context_create").First(&User{}).RecordNotFound() {
+		t.Errorf("Record shouldn't be created with cancelled context")
+	}
+
+	if err := DB.WithContext(ctx).Model(&user).Update("name", "cancelled_context_update").Error; err != context.Canceled {
+		t.Errorf("Update with cancelled context should return context.Canceled, but got %v", err)
+	}
+
+	if err := DB.WithContext(ctx).Delete(&user).Error; err != context.Canceled {
+		t.Errorf("Delete with cancelled context should return context.Canceled, but got %v", err)
+	}
+
+	if DB.First(&User{}, user.Id).RecordNotFound() {
+		t.Errorf("Record shouldn't be deleted with cancelled context")
+	}
+
+	if _, err := DB.WithContext(ctx).Model(&User{}).Rows(); err != context.Canceled {
+		t.Errorf("Rows with cancelled context should return context.Canceled, but got %v", err)
+	}
+
+	if err := DB.WithContext(ctx).Begin().Error; err != context.Canceled {
+		t.Errorf("Begin with cancelled context should return context.Canceled, but got %v", err)
+	}
+}
+
+func TestTransactionWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tx := DB.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		t.Fatalf("No error should happen when begin transaction, but got %v", tx.Error)
+	}
+
+	if err := tx.Save(&User{Name: "context_transaction"}).Error; err != nil {
+		t.Errorf("No error should happen when saving in transaction, but got %v", err)
+	}
+
+	cancel()
+
+	if err := tx.Commit().Error; err == nil {
+		t.Errorf("Commit should fail after context cancelled")
+	}
+
+	if !DB.Where("name = ?", "context_transaction").First(&User{}).RecordNotFound() {
+		t.Errorf("Transaction should be rolled back after context cancelled")
+	}
+}
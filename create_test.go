@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jinzhu/gorm"
 	"github.com/jinzhu/now"
 )
 
@@ -229,3 +230,256 @@ func TestOmitWithCreate(t *testing.T) {
 		t.Errorf("Should not create omitted relationships")
 	}
 }
+
+func TestCreateInBatches(t *testing.T) {
+	users := []User{
+		{Name: "batch_create_user1", Age: 18, Emails: []Email{{Email: "batch_create_user1@example.org"}}},
+		{Name: "batch_create_user2", Age: 19},
+		{Name: "batch_create_user3", Age: 20, Emails: []Email{{Email: "batch_create_user3@example.org"}, {Email: "batch_create_user3@example.com"}}},
+	}
+
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("No error should happen when creating users in batches, but got %v", err)
+	}
+
+	for _, user := range users {
+		if user.Id == 0 {
+			t.Errorf("Primary key should be set back to %v after batch create", user.Name)
+		}
+
+		if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+			t.Errorf("Timestamps should be set for %v after batch create", user.Name)
+		}
+
+		var newUser User
+		if err := DB.Preload("Emails").First(&newUser, user.Id).Error; err != nil {
+			t.Errorf("No error should happen when querying %v, but got %v", user.Name, err)
+		} else if newUser.Name != user.Name || newUser.Age != user.Age {
+			t.Errorf("%v should be created with primary key %v, but found %v", user.Name, user.Id, newUser.Name)
+		} else if len(newUser.Emails) != len(user.Emails) {
+			t.Errorf("%v's emails should be saved after batch create, expect %v, got %v", user.Name, len(user.Emails), len(newUser.Emails))
+		}
+	}
+}
+
+func TestCreateInBatchesWithPointers(t *testing.T) {
+	products := []*Product{{Code: "batch_create_product1"}, {Code: "batch_create_product2"}, {Code: "batch_create_product3"}}
+
+	if err := DB.Set("gorm:batch_size", 2).Create(&products).Error; err != nil {
+		t.Fatalf("No error should happen when creating products in batches, but got %v", err)
+	}
+
+	for _, product := range products {
+		if product.BeforeCreateCallTimes != 1 || product.BeforeSaveCallTimes != 1 || product.AfterSaveCallTimes != 1 {
+			t.Errorf("Callbacks should be called for every product, but got %v", product.GetCallTimes())
+		}
+
+		var newProduct Product
+		if err := DB.First(&newProduct, product.Id).Error; err != nil || newProduct.Code != product.Code {
+			t.Errorf("%v should be created with primary key %v", product.Code, product.Id)
+		} else if newProduct.AfterCreateCallTimes != 1 {
+			t.Errorf("AfterCreate should be called for %v", product.Code)
+		}
+	}
+
+	invalidProducts := []Product{{Code: "batch_create_product4"}, {Code: "Invalid"}}
+	if err := DB.Create(&invalidProducts).Error; err == nil {
+		t.Errorf("Should got error when a BeforeCreate callback failed")
+	}
+
+	if !DB.First(&Product{}, "code = ?", "batch_create_product4").RecordNotFound() {
+		t.Errorf("No product should be created when a BeforeCreate callback failed")
+	}
+}
+
+func TestCreateInBatchesWithMixedPrimaryKeys(t *testing.T) {
+	type BatchCreateItem struct {
+		ID   uint
+		Name string
+	}
+
+	DB.DropTableIfExists(&BatchCreateItem{})
+	DB.AutoMigrate(&BatchCreateItem{})
+
+	items := [4]BatchCreateItem{{Name: "item1"}, {ID: 100, Name: "item2"}, {Name: "item3"}, {}}
+	if err := DB.Create(&items).Error; err != nil {
+		t.Fatalf("No error should happen when creating items in batches, but got %v", err)
+	}
+
+	var ids = map[uint]bool{}
+	for _, item := range items {
+		var newItem BatchCreateItem
+		if item.ID == 0 || ids[item.ID] {
+			t.Errorf("Item should have a unique primary key, but got %v", item.ID)
+		} else if err := DB.First(&newItem, item.ID).Error; err != nil || newItem.Name != item.Name {
+			t.Errorf("Item %v should be created with primary key %v", item.Name, item.ID)
+		}
+		ids[item.ID] = true
+	}
+
+	if items[1].ID != 100 {
+		t.Errorf("Primary key set before creating should be kept")
+	}
+
+	var count int
+	if DB.Model(&BatchCreateItem{}).Count(&count); count != len(items) {
+		t.Errorf("%v items should be created, but got %v", len(items), count)
+	}
+}
+
+type UpsertUser struct {
+	ID        uint
+	Email     string `gorm:"unique_index"`
+	Name      string
+	Age       int
+	CreatedAt time.Time
+}
+
+func TestCreateOnConflict(t *testing.T) {
+	DB.DropTableIfExists(&UpsertUser{})
+	DB.AutoMigrate(&UpsertUser{})
+
+	user := UpsertUser{Email: "upsert@example.org", Name: "upsert", Age: 18}
+	if err := DB.OnConflict("email").DoUpdate("name").Create(&user).Error; err != nil {
+		t.Fatalf("No error should happen when creating user without conflicts, but got %v", err)
+	}
+
+	// primary key is only returned by dialects using `RETURNING` or `OUTPUT` when upserting
+	DB.First(&user, "email = ?", user.Email)
+
+	if err := DB.OnConflict("email").DoUpdate("Name").Create(&UpsertUser{Email: "upsert@example.org", Name: "upsert_new", Age: 20}).Error; err != nil {
+		t.Errorf("No error should happen when upserting, but got %v", err)
+	}
+
+	var newUser UpsertUser
+	DB.First(&newUser, user.ID)
+	if newUser.Name != "upsert_new" || newUser.Age != 18 {
+		t.Errorf("Only name should be updated when conflicted, but got %+v", newUser)
+	}
+
+	if err := DB.OnConflict("email").DoNothing().Create(&UpsertUser{Email: "upsert@example.org", Name: "upsert_skipped"}).Error; err != nil {
+		t.Errorf("No error should happen when skipping conflicted record, but got %v", err)
+	}
+
+	DB.First(&newUser, user.ID)
+	if newUser.Name != "upsert_new" {
+		t.Errorf("Conflicted record should be skipped, but got %+v", newUser)
+	}
+
+	if err := DB.Create(&UpsertUser{Email: "upsert@example.org"}).Error; err == nil {
+		t.Errorf("Should got error when creating conflicted record without OnConflict")
+	}
+}
+
+func TestCreateOnConflictDefaultTarget(t *testing.T) {
+	DB.DropTableIfExists(&UpsertUser{})
+	DB.AutoMigrate(&UpsertUser{})
+
+	// the auto increment primary key isn't inserted, conflicts are resolved by the unique index of email
+	DB.Create(&UpsertUser{Email: "default_target@example.org", Name: "default_target"})
+	if err := DB.OnConflict().DoUpdate("name").Create(&UpsertUser{Email: "default_target@example.org", Name: "default_target_new"}).Error; err != nil {
+		t.Errorf("No error should happen when upserting without conflict target, but got %v", err)
+	}
+
+	var user UpsertUser
+	if DB.First(&user, "email = ?", "default_target@example.org"); user.Name != "default_target_new" {
+		t.Errorf("Conflicted record should be updated by the unique index, but got %+v", user)
+	}
+}
+
+func TestCreateInBatchesOnConflict(t *testing.T) {
+	DB.DropTableIfExists(&UpsertUser{})
+	DB.AutoMigrate(&UpsertUser{})
+
+	users := []UpsertUser{{Email: "upsert1@example.org", Name: "upsert1", Age: 18}, {Email: "upsert2@example.org", Name: "upsert2", Age: 18}}
+	DB.Create(&users)
+
+	newUsers := []UpsertUser{
+		{Email: "upsert1@example.org", Name: "upsert1_new", Age: 20, CreatedAt: time.Now().Add(time.Hour)},
+		{Email: "upsert3@example.org", Name: "upsert3", Age: 20},
+	}
+	if err := DB.OnConflict("email").UpdateAll().Create(&newUsers).Error; err != nil {
+		t.Errorf("No error should happen when upserting users in batches, but got %v", err)
+	}
+
+	var user UpsertUser
+	DB.First(&user, users[0].ID)
+	if user.Name != "upsert1_new" || user.Age != 20 {
+		t.Errorf("All columns should be updated when conflicted, but got %+v", user)
+	}
+
+	if user.CreatedAt.Sub(users[0].CreatedAt) > time.Minute {
+		t.Errorf("CreatedAt should not be updated when conflicted")
+	}
+
+	var newUser UpsertUser
+	if DB.First(&newUser, "email = ?", "upsert3@example.org").RecordNotFound() || newUser.Name != "upsert3" {
+		t.Errorf("Record without conflicts should be created")
+	}
+
+	if err := DB.OnConflict("email").DoNothing().Create(&[]UpsertUser{{Email: "upsert2@example.org", Name: "upsert2_new"}, {Email: "upsert4@example.org"}}).Error; err != nil {
+		t.Errorf("No error should happen when skipping conflicted users in batches, but got %v", err)
+	}
+
+	var count int
+	if DB.Model(&UpsertUser{}).Count(&count); count != 4 {
+		t.Errorf("4 users should be saved, but got %v", count)
+	}
+
+	var skippedUser UpsertUser
+	if DB.First(&skippedUser, users[1].ID); skippedUser.Name != "upsert2" {
+		t.Errorf("Conflicted user should be skipped, but got %+v", skippedUser)
+	}
+}
+
+type AutoTimeUser struct {
+	ID           uint
+	Name         string
+	Created      time.Time `gorm:"autoCreateTime"`
+	CreatedMilli int64     `gorm:"autoCreateTime:milli"`
+	Updated      int64     `gorm:"autoUpdateTime"`
+	UpdatedNano  int64     `gorm:"autoUpdateTime:nano"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime:false"`
+}
+
+func TestAutoTimeTags(t *testing.T) {
+	var now = time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	db, err := gorm.Open(DB.Dialect().GetName(), DB.DB())
+	if err != nil {
+		t.Fatalf("Failed to open db, got error %v", err)
+	}
+	db.SetNowFunc(func() time.Time { return now })
+
+	db.DropTableIfExists(&AutoTimeUser{})
+	db.AutoMigrate(&AutoTimeUser{})
+
+	user := AutoTimeUser{Name: "auto_time"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("Failed to create user, got error %v", err)
+	}
+
+	if !user.Created.Equal(now) || user.CreatedMilli != now.UnixNano()/int64(time.Millisecond) || user.Updated != now.Unix() || user.UpdatedNano != now.UnixNano() {
+		t.Errorf("Auto timestamps should be set when creating, but got %#v", user)
+	}
+
+	if !user.UpdatedAt.IsZero() {
+		t.Errorf("UpdatedAt tagged with `autoUpdateTime:false` should not be set, but got %v", user.UpdatedAt)
+	}
+
+	created := now
+	now = now.Add(time.Hour)
+	if err := db.Model(&user).Update("name", "auto_time_updated").Error; err != nil {
+		t.Fatalf("Failed to update user, got error %v", err)
+	}
+
+	var result AutoTimeUser
+	db.First(&result, user.ID)
+	if !result.Created.Equal(created) || result.CreatedMilli != created.UnixNano()/int64(time.Millisecond) {
+		t.Errorf("Auto create timestamps should not be changed when updating, but got %#v", result)
+	}
+
+	if result.Updated != now.Unix() || result.UpdatedNano != now.UnixNano() {
+		t.Errorf("Auto update timestamps should be set when updating, but got %#v", result)
+	}
+}
@@ -3,6 +3,8 @@ package gorm_test
 import (
 	"testing"
 	"time"
+
+	"github.com/jinzhu/gorm"
 )
 
 func TestDelete(t *testing.T) {
@@ -89,3 +91,100 @@ func TestSoftDeleteWithCustomizedDeletedAtColumnName(t *testing.T) {
 		t.Errorf("Can't find permanently deleted record")
 	}
 }
+
+type UnixDeletedUser struct {
+	ID        uint
+	Name      string `gorm:"unique_index:idx_unix_deleted_user_name"`
+	DeletedAt int64  `gorm:"softDelete:unix;unique_index:idx_unix_deleted_user_name"`
+	DeletedBy string `gorm:"deletedBy"`
+}
+
+type FlagDeletedUser struct {
+	ID        uint
+	Name      string
+	IsDeleted bool `gorm:"softDelete"`
+}
+
+func TestSoftDeleteWithUnixSeconds(t *testing.T) {
+	DB.DropTableIfExists(&UnixDeletedUser{})
+	DB.AutoMigrate(&UnixDeletedUser{})
+
+	user := UnixDeletedUser{Name: "unix_soft_delete"}
+	DB.Save(&user)
+	if err := DB.Set("gorm:deleted_by", "admin").Delete(&user).Error; err != nil {
+		t.Fatalf("Failed to delete user, got error %v", err)
+	}
+
+	if !DB.First(&UnixDeletedUser{}, "name = ?", user.Name).RecordNotFound() {
+		t.Errorf("Soft deleted record should not be found")
+	}
+
+	var deleted UnixDeletedUser
+	if err := DB.OnlyDeleted().First(&deleted, "name = ?", user.Name).Error; err != nil {
+		t.Errorf("Soft deleted record should be found with OnlyDeleted, but got %v", err)
+	}
+
+	if deleted.DeletedAt == 0 || deleted.DeletedBy != "admin" {
+		t.Errorf("Soft delete fields should be set, but got %#v", deleted)
+	}
+
+	// records not deleted are 0, so the unique index allows a new record with the same name
+	if err := DB.Save(&UnixDeletedUser{Name: user.Name}).Error; err != nil {
+		t.Errorf("Should be able to create a record with the name of a soft deleted one, but got %v", err)
+	}
+
+	var count int
+	DB.Model(&UnixDeletedUser{}).OnlyDeleted().Count(&count)
+	if count != 1 {
+		t.Errorf("OnlyDeleted should only count deleted records, but got %v", count)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	DB.DropTableIfExists(&FlagDeletedUser{})
+	DB.AutoMigrate(&FlagDeletedUser{})
+
+	user1, user2 := FlagDeletedUser{Name: "restore1"}, FlagDeletedUser{Name: "restore2"}
+	DB.Save(&user1).Save(&user2)
+	DB.Delete(&user1)
+	DB.Delete(&user2)
+
+	if DB.OnlyDeleted().First(&FlagDeletedUser{}, user1.ID).RecordNotFound() {
+		t.Errorf("Flag soft deleted record should be found with OnlyDeleted")
+	}
+
+	if err := DB.Restore(&user1).Error; err != nil {
+		t.Errorf("Failed to restore record, got error %v", err)
+	}
+
+	if user1.IsDeleted {
+		t.Errorf("Restored record should not be marked as deleted")
+	}
+
+	if err := DB.First(&FlagDeletedUser{}, user1.ID).Error; err != nil {
+		t.Errorf("Restored record should be found, but got %v", err)
+	}
+
+	if err := DB.Unscoped().Model(&FlagDeletedUser{}).Where("name = ?", user2.Name).Restore().Error; err != nil {
+		t.Errorf("Failed to restore records, got error %v", err)
+	}
+
+	var count int
+	DB.Model(&FlagDeletedUser{}).Count(&count)
+	if count != 2 {
+		t.Errorf("All records should be restored, but got %v", count)
+	}
+
+	if err := DB.Restore(&User{}).Error; err == nil {
+		t.Errorf("Should got error when restoring models not soft deleted")
+	}
+
+	DB.Delete(&user1)
+	if err := DB.Restore(&FlagDeletedUser{}).Error; err != gorm.ErrMissingWhereClause {
+		t.Errorf("Should got ErrMissingWhereClause when restoring without primary keys or conditions, but got %v", err)
+	}
+
+	if !DB.First(&FlagDeletedUser{}, user1.ID).RecordNotFound() {
+		t.Errorf("Records should not be restored without primary keys or conditions")
+	}
+}
@@ -2,10 +2,13 @@ package gorm
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Dialect interface contains behaviors that differ across SQL database
@@ -52,6 +55,239 @@ type Dialect interface {
 	CurrentDatabase() string
 }
 
+// ColumnType column of a table in database, returned by `IntrospectionDialect.ColumnTypes`
+type ColumnType struct {
+	Name string
+	// DatabaseType type of the column like `varchar(255)`
+	DatabaseType string
+	Nullable     bool
+	// DefaultValue default value expression of the column, invalid if there is no default value
+	DefaultValue sql.NullString
+	// Length length of string or binary columns, 0 if unknown
+	Length int64
+	// Precision and Scale of numeric columns, 0 if unknown
+	Precision     int64
+	Scale         int64
+	PrimaryKey    bool
+	AutoIncrement bool
+	Comment       string
+}
+
+// Index index of a table in database, returned by `IntrospectionDialect.GetIndexes`
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Primary bool
+
+	// options below are declared with the `index` tag of models, they are not returned by `IntrospectionDialect.GetIndexes`
+
+	// Sorts sort of each column like `DESC`, in the same order of Columns
+	Sorts []string
+	// Lengths prefix length of each column, in the same order of Columns
+	Lengths []int
+	// Type index method like `btree`, `hash` or `gin`
+	Type string
+	// Where condition of partial indexes like `deleted_at IS NULL`
+	Where string
+	// Option like `CONCURRENTLY`
+	Option  string
+	Comment string
+}
+
+// ForeignKey foreign key constraint of a table in database, returned by `IntrospectionDialect.GetForeignKeys`
+type ForeignKey struct {
+	Name             string
+	Columns          []string
+	ReferenceTable   string
+	ReferenceColumns []string
+	// OnUpdate and OnDelete actions like `CASCADE`, `SET NULL` and `NO ACTION`
+	OnUpdate string
+	OnDelete string
+}
+
+// OnConflict describe how to resolve conflicts when creating records, columns are quoted before passing to dialects
+type OnConflict struct {
+	// Columns conflict target, primary keys will be used if empty
+	Columns []string
+	// DoUpdates columns to update with the inserted values when conflicted
+	DoUpdates []string
+	// DoNothing skip conflicted rows
+	DoNothing bool
+	// UpdateAll update all inserted columns except primary keys, conflict target and `created_at` when conflicted
+	UpdateAll bool
+}
+
+// UpsertDialect could be implemented by dialects whose syntax resolving conflicts differs from `INSERT ... ON CONFLICT`,
+// e.g. mysql uses `ON DUPLICATE KEY UPDATE`, mssql uses `MERGE`
+type UpsertDialect interface {
+	// UpsertSQL return the insert statement of rows that resolves conflicts as described by onConflict, output is the clause
+	// returned by LastInsertIDOutputInterstitial, an error is returned if conflicts can't be resolved as described
+	UpsertSQL(tableName string, columns []string, rows []string, output string, onConflict OnConflict) (string, error)
+}
+
+// upsertSQL return the insert statement of rows that resolves conflicts, most dbs use `ON CONFLICT`,
+// conflicted columns are updated with values from `EXCLUDED`
+func upsertSQL(dialect Dialect, tableName string, columns []string, rows []string, output string, onConflict OnConflict) (string, error) {
+	if dialect, ok := dialect.(UpsertDialect); ok {
+		return dialect.UpsertSQL(tableName, columns, rows, output, onConflict)
+	}
+
+	var conflictTarget string
+	if len(onConflict.Columns) > 0 {
+		conflictTarget = fmt.Sprintf(" (%v)", strings.Join(onConflict.Columns, ","))
+	}
+
+	action := "DO NOTHING"
+	if !onConflict.DoNothing && len(onConflict.DoUpdates) > 0 {
+		var assignments []string
+		for _, column := range onConflict.DoUpdates {
+			assignments = append(assignments, fmt.Sprintf("%v = EXCLUDED.%v", column, column))
+		}
+		action = "DO UPDATE SET " + strings.Join(assignments, ",")
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %v (%v)%v VALUES %v ON CONFLICT%v %v",
+		tableName,
+		strings.Join(columns, ","),
+		addExtraSpaceIfExist(output),
+		strings.Join(rows, ","),
+		conflictTarget,
+		action,
+	), nil
+}
+
+// Locking row locking clause used with `Clauses`, e.g. `Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}`
+type Locking struct {
+	// Strength UPDATE or SHARE
+	Strength string
+	// Options NOWAIT or SKIP LOCKED
+	Options string
+	// Of table to lock when joining tables
+	Of string
+}
+
+// LockingDialect could be implemented by dialects whose row locking differs from `FOR UPDATE`
+type LockingDialect interface {
+	// LockingSQL return the clause appended to the query, and the hint following the table name
+	LockingSQL(locking Locking) (clause string, tableHint string)
+}
+
+// KeyNameLengthDialect could be implemented by dialects limiting the length of index and constraint names,
+// longer names built by custom naming strategies will be shortened with a hash, refer `BuildKeyName` for the default one
+type KeyNameLengthDialect interface {
+	MaxKeyNameLength() int
+}
+
+// RowValueDialect could be implemented by dialects to tell whether row value comparisons like `(a, b) > (?, ?)` are supported,
+// they are supported unless the dialect says otherwise
+type RowValueDialect interface {
+	SupportRowValues() bool
+}
+
+// TransactionalDDLDialect could be implemented by dialects to tell whether DDL statements could be rolled back in transactions,
+// they could unless the dialect says otherwise
+type TransactionalDDLDialect interface {
+	SupportTransactionalDDL() bool
+}
+
+// IntrospectionDialect could be implemented by dialects to inspect schemas of tables in database,
+// `Migrator` returns ErrNotSupported for dialects without it
+type IntrospectionDialect interface {
+	// GetTables return tables of current database
+	GetTables() ([]string, error)
+	// ColumnTypes return columns of the table in database
+	ColumnTypes(tableName string) ([]ColumnType, error)
+	// GetIndexes return indexes of the table in database, indexes created by unique constraints are excluded if the database tells them apart
+	GetIndexes(tableName string) ([]Index, error)
+	// GetForeignKeys return foreign key constraints of the table in database
+	GetForeignKeys(tableName string) ([]ForeignKey, error)
+}
+
+// AlterTableDialect could be implemented by dialects to alter tables, `DB.AlterColumn`, `DB.RenameColumn` and so on
+// return ErrNotSupported for dialects without it
+type AlterTableDialect interface {
+	// AlterColumn change the column to the definition of the field, including its type, nullability and default value
+	AlterColumn(tableName string, field *StructField) error
+	// RenameTable rename the table
+	RenameTable(oldName string, newName string) error
+	// RenameColumn rename the column of the table
+	RenameColumn(tableName string, oldName string, newName string) error
+	// RenameIndex rename the index of the table
+	RenameIndex(tableName string, oldName string, newName string) error
+	// DropConstraint drop the constraint of the table, like foreign keys and checks
+	DropConstraint(tableName string, constraintName string) error
+}
+
+// AlterColumnDialect could be implemented by dialects whose syntax altering columns differs from `ALTER TABLE ... ALTER COLUMN ...`,
+// kind is one of `AlterColumnTypeChange`, `AlterColumnNullChange` and `AlterColumnDefaultChange`
+type AlterColumnDialect interface {
+	// AlterColumnSQL return the statement changing the column to the definition of the field, blank if columns can't be altered with
+	// statements, then `Migrator` alters them with `AlterTableDialect.AlterColumn` when applying
+	AlterColumnSQL(kind SchemaChangeKind, tableName string, field *StructField) string
+}
+
+// AddConstraintDialect could be implemented by dialects that can't add constraints with `ALTER TABLE ... ADD CONSTRAINT`
+type AddConstraintDialect interface {
+	// AddConstraint add the constraint definition to the table
+	AddConstraint(tableName string, definition string) error
+}
+
+// DropIndexDialect could be implemented by dialects whose syntax dropping indexes differs from `DROP INDEX name`
+type DropIndexDialect interface {
+	// DropIndexSQL return the statement dropping the index of the table
+	DropIndexSQL(tableName string, indexName string) string
+}
+
+// CreateIndexDialect could be implemented by dialects whose syntax creating indexes differs from
+// `CREATE [UNIQUE] INDEX [option] name ON table [USING type] (columns) [WHERE condition]`
+type CreateIndexDialect interface {
+	// CreateIndexSQL return statements creating the index of the table, the table name and column names of the index are quoted,
+	// return an error if the index has options the dialect can't create like `Where`
+	CreateIndexSQL(quotedTableName string, index Index) ([]string, error)
+}
+
+// SavePointDialect could be implemented by dialects whose savepoint syntax differs from `SAVEPOINT name`
+type SavePointDialect interface {
+	// SavePointSQL return the SQL used to create a savepoint
+	SavePointSQL(name string) string
+	// RollbackToSavePointSQL return the SQL used to rollback to a savepoint
+	RollbackToSavePointSQL(name string) string
+	// ReleaseSavePointSQL return the SQL used to release a savepoint, blank if savepoints can't be released
+	ReleaseSavePointSQL(name string) string
+}
+
+// MaxBindVarsDialect could be implemented by dialects that limit the number of bind variables in a statement,
+// batch inserts will be split to stay under the limit
+type MaxBindVarsDialect interface {
+	MaxBindVars() int
+}
+
+// LastInsertIDOutputDialect could be implemented by dialects returning inserted IDs with a clause between the table and values,
+// most dbs support LastInsertId, but mssql needs to use `OUTPUT`
+type LastInsertIDOutputDialect interface {
+	LastInsertIDOutputInterstitial(tableName, columnName string, columns []string) string
+}
+
+// MaxInsertRowsDialect could be implemented by dialects that limit the number of rows inserted with one statement,
+// batch inserts will be split to stay under the limit
+type MaxInsertRowsDialect interface {
+	MaxInsertRows() int
+}
+
+// ErrorTranslatorDialect could be implemented by dialects to translate driver errors to gorm's errors
+type ErrorTranslatorDialect interface {
+	// TranslateError return the error that err is translated to, like ErrDuplicatedKey, or nil if it can't be translated,
+	// the original error is kept and could be matched with `errors.Is` and `errors.As`
+	TranslateError(err error) error
+}
+
+// lastInsertIDOfLastRowDialect is implemented by dialects whose LastInsertId of a multi-row insert returns the id of the last row, instead of the first one
+type lastInsertIDOfLastRowDialect interface {
+	lastInsertIDOfLastRow() bool
+}
+
 var dialectsMap = map[string]Dialect{}
 
 func newDialect(name string, db SQLCommon) Dialect {
@@ -67,6 +303,17 @@ func newDialect(name string, db SQLCommon) Dialect {
 	return commontDialect
 }
 
+// transactionDialect return the dialect running statements in the transaction, only sqlite needs it as tables are rebuilt
+// with several statements that must run in the transaction, nil for other dialects which keep running on the db
+func transactionDialect(dialect Dialect, tx SQLCommon) Dialect {
+	if dialect, ok := dialect.(*sqlite3); ok {
+		clone := *dialect
+		clone.SetDB(tx)
+		return &clone
+	}
+	return nil
+}
+
 // RegisterDialect register new dialect
 func RegisterDialect(name string, dialect Dialect) {
 	dialectsMap[name] = dialect
@@ -93,6 +340,15 @@ var ParseFieldStructForDialect = func(field *StructField, dialect Dialect) (fiel
 		dataType = gormDataType.GormDataType(dialect)
 	}
 
+	// Get serializer's data type
+	if serializer, _ := field.serializer(); serializer != nil && field.TagSettings["TYPE"] == "" {
+		if gormDataType, ok := serializer.(interface {
+			GormDataType(Dialect) string
+		}); ok {
+			dataType = gormDataType.GormDataType(dialect)
+		}
+	}
+
 	// Get scanner's real value
 	if dataType == "" {
 		var getScannerValue func(reflect.Value)
@@ -128,3 +384,260 @@ func currentDatabaseAndTable(dialect Dialect, tableName string) (string, string)
 	}
 	return dialect.CurrentDatabase(), tableName
 }
+
+func supportRowValues(dialect Dialect) bool {
+	if dialect, ok := dialect.(RowValueDialect); ok {
+		return dialect.SupportRowValues()
+	}
+	return true
+}
+
+func lockingSQL(dialect Dialect, locking Locking) (clause string, tableHint string) {
+	if dialect, ok := dialect.(LockingDialect); ok {
+		return dialect.LockingSQL(locking)
+	}
+
+	clause = "FOR " + locking.Strength
+	if locking.Of != "" {
+		clause += " OF " + dialect.Quote(locking.Of)
+	}
+	if locking.Options != "" {
+		clause += " " + locking.Options
+	}
+	return clause, ""
+}
+
+func lastInsertIDOutputInterstitial(dialect Dialect, tableName, columnName string, columns []string) string {
+	if dialect, ok := dialect.(LastInsertIDOutputDialect); ok {
+		return dialect.LastInsertIDOutputInterstitial(tableName, columnName, columns)
+	}
+	return ""
+}
+
+func savePointSQL(dialect Dialect, name string) string {
+	if dialect, ok := dialect.(SavePointDialect); ok {
+		return dialect.SavePointSQL(name)
+	}
+	return fmt.Sprintf("SAVEPOINT %v", name)
+}
+
+func rollbackToSavePointSQL(dialect Dialect, name string) string {
+	if dialect, ok := dialect.(SavePointDialect); ok {
+		return dialect.RollbackToSavePointSQL(name)
+	}
+	return fmt.Sprintf("ROLLBACK TO SAVEPOINT %v", name)
+}
+
+func releaseSavePointSQL(dialect Dialect, name string) string {
+	if dialect, ok := dialect.(SavePointDialect); ok {
+		return dialect.ReleaseSavePointSQL(name)
+	}
+	return fmt.Sprintf("RELEASE SAVEPOINT %v", name)
+}
+
+func alterColumnSQL(dialect Dialect, kind SchemaChangeKind, tableName string, field *StructField) string {
+	if dialect, ok := dialect.(AlterColumnDialect); ok {
+		return dialect.AlterColumnSQL(kind, tableName, field)
+	}
+
+	var (
+		prefix     = fmt.Sprintf("ALTER TABLE %v ALTER COLUMN %v", dialect.Quote(tableName), dialect.Quote(field.DBName))
+		_, notNull = field.TagSettings["NOT NULL"]
+	)
+
+	switch kind {
+	case AlterColumnNullChange:
+		if notNull {
+			return prefix + " SET NOT NULL"
+		}
+		return prefix + " DROP NOT NULL"
+	case AlterColumnDefaultChange:
+		if value, ok := field.TagSettings["DEFAULT"]; ok {
+			return prefix + " SET DEFAULT " + value
+		}
+		return prefix + " DROP DEFAULT"
+	}
+	return prefix + " TYPE " + columnDataType(dialect, field)
+}
+
+// alterColumn execute statements changing the column to the definition of the field, the same statements are executed once
+func alterColumn(db SQLCommon, dialect Dialect, tableName string, field *StructField) error {
+	var lastSQL string
+	for _, kind := range []SchemaChangeKind{AlterColumnTypeChange, AlterColumnNullChange, AlterColumnDefaultChange} {
+		if alterSQL := alterColumnSQL(dialect, kind, tableName, field); alterSQL != lastSQL {
+			if _, err := db.Exec(alterSQL); err != nil {
+				return err
+			}
+			lastSQL = alterSQL
+		}
+	}
+	return nil
+}
+
+func dropIndexSQL(dialect Dialect, tableName string, indexName string) string {
+	if dialect, ok := dialect.(DropIndexDialect); ok {
+		return dialect.DropIndexSQL(tableName, indexName)
+	}
+	return fmt.Sprintf("DROP INDEX %v", indexName)
+}
+
+// createIndexSQL return statements creating the index of the table
+func createIndexSQL(dialect Dialect, quotedTableName string, index Index) ([]string, error) {
+	if dialect, ok := dialect.(CreateIndexDialect); ok {
+		return dialect.CreateIndexSQL(quotedTableName, index)
+	}
+	return []string{defaultCreateIndexSQL(quotedTableName, index)}, nil
+}
+
+func defaultCreateIndexSQL(quotedTableName string, index Index) string {
+	sql := "CREATE INDEX"
+	if index.Unique {
+		sql = "CREATE UNIQUE INDEX"
+	}
+	if index.Option != "" {
+		sql += " " + index.Option
+	}
+
+	sql += fmt.Sprintf(" %v ON %v", index.Name, quotedTableName)
+	if index.Type != "" {
+		sql += " USING " + index.Type
+	}
+
+	sql += fmt.Sprintf("(%v)", strings.Join(indexColumnsSQL(index, false), ", "))
+	if index.Where != "" {
+		sql += " WHERE " + index.Where
+	}
+	return sql
+}
+
+// indexColumnsSQL return columns of the index with their sorts, prefix lengths are included if withLength is true
+func indexColumnsSQL(index Index, withLength bool) (columns []string) {
+	for idx, column := range index.Columns {
+		if withLength && idx < len(index.Lengths) && index.Lengths[idx] > 0 {
+			column += fmt.Sprintf("(%d)", index.Lengths[idx])
+		}
+		if idx < len(index.Sorts) && index.Sorts[idx] != "" {
+			column += " " + index.Sorts[idx]
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// quoteString quote the string as a SQL string literal
+func quoteString(str string) string {
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
+
+// literalSQL format the value as a SQL literal, used by statements that can't have bind vars like `CREATE INDEX`
+func literalSQL(value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		var err error
+		if value, err = valuer.Value(); err != nil {
+			return "NULL"
+		}
+	}
+
+	indirectValue := reflect.Indirect(reflect.ValueOf(value))
+	if !indirectValue.IsValid() {
+		return "NULL"
+	}
+
+	switch value := indirectValue.Interface().(type) {
+	case string:
+		return quoteString(value)
+	case []byte:
+		return quoteString(string(value))
+	case time.Time:
+		return quoteString(value.Format("2006-01-02 15:04:05.999999"))
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(value))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(value)
+	default:
+		return quoteString(fmt.Sprint(value))
+	}
+}
+
+// columnDataType return the data type of the field without constraints like `NOT NULL`, `UNIQUE` and `DEFAULT`
+func columnDataType(dialect Dialect, field *StructField) string {
+	_, _, _, additionalType := ParseFieldStructForDialect(field, dialect)
+	return strings.TrimSpace(strings.TrimSuffix(dialect.DataTypeOf(field), additionalType))
+}
+
+// scanForeignKeys group rows of constraint name, column name, reference table, reference column, on update and on delete to foreign keys
+func scanForeignKeys(rows *sql.Rows) (foreignKeys []ForeignKey, err error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, column, referenceTable, referenceColumn, onUpdate, onDelete string
+		if err = rows.Scan(&name, &column, &referenceTable, &referenceColumn, &onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+
+		if len(foreignKeys) == 0 || foreignKeys[len(foreignKeys)-1].Name != name {
+			foreignKeys = append(foreignKeys, ForeignKey{Name: name, ReferenceTable: referenceTable, OnUpdate: onUpdate, OnDelete: onDelete})
+		}
+		foreignKey := &foreignKeys[len(foreignKeys)-1]
+		foreignKey.Columns = append(foreignKey.Columns, column)
+		foreignKey.ReferenceColumns = append(foreignKey.ReferenceColumns, referenceColumn)
+	}
+	return foreignKeys, rows.Err()
+}
+
+// scanStrings return strings of the first column of rows
+func scanStrings(rows *sql.Rows) (values []string, err error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var value string
+		if err = rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+var dataTypeArgsRegexp = regexp.MustCompile(`\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+
+// parseDataTypeArgs set length, precision and scale of the column type from its declared type like `varchar(255)` or `decimal(10,2)`
+func parseDataTypeArgs(columnType *ColumnType) {
+	matches := dataTypeArgsRegexp.FindStringSubmatch(columnType.DatabaseType)
+	if len(matches) == 0 {
+		return
+	}
+
+	first, _ := strconv.ParseInt(matches[1], 10, 64)
+	switch name := strings.ToLower(columnType.DatabaseType); {
+	case strings.Contains(name, "char"), strings.Contains(name, "binary"), strings.Contains(name, "blob"), strings.Contains(name, "text"):
+		columnType.Length = first
+	default:
+		columnType.Precision = first
+		if matches[2] != "" {
+			columnType.Scale, _ = strconv.ParseInt(matches[2], 10, 64)
+		}
+	}
+}
+
+// scanIndexes group rows of index name, column name, unique and primary to indexes
+func scanIndexes(rows *sql.Rows) (indexes []Index, err error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name, column    string
+			unique, primary bool
+		)
+
+		if err = rows.Scan(&name, &column, &unique, &primary); err != nil {
+			return nil, err
+		}
+
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, Index{Name: name, Unique: unique, Primary: primary})
+		}
+		indexes[len(indexes)-1].Columns = append(indexes[len(indexes)-1].Columns, column)
+	}
+	return indexes, rows.Err()
+}
@@ -1,6 +1,7 @@
 package gorm
 
 import (
+	"database/sql"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -110,7 +111,10 @@ func (s commonDialect) RemoveIndex(tableName string, indexName string) error {
 }
 
 func (s commonDialect) HasForeignKey(tableName string, foreignKeyName string) bool {
-	return false
+	var count int
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	s.db.QueryRow("SELECT count(*) FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS WHERE constraint_schema = ? AND table_name = ? AND constraint_name = ? AND constraint_type = 'FOREIGN KEY'", currentDatabase, tableName, foreignKeyName).Scan(&count)
+	return count > 0
 }
 
 func (s commonDialect) HasTable(tableName string) bool {
@@ -132,6 +136,95 @@ func (s commonDialect) ModifyColumn(tableName string, columnName string, typ str
 	return err
 }
 
+func (s *commonDialect) AlterColumn(tableName string, field *StructField) error {
+	return alterColumn(s.db, s, tableName, field)
+}
+
+func (s commonDialect) RenameTable(oldName string, newName string) error {
+	_, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %v RENAME TO %v", s.Quote(oldName), s.Quote(newName)))
+	return err
+}
+
+func (s commonDialect) RenameColumn(tableName string, oldName string, newName string) error {
+	_, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %v RENAME COLUMN %v TO %v", s.Quote(tableName), s.Quote(oldName), s.Quote(newName)))
+	return err
+}
+
+func (s commonDialect) RenameIndex(tableName string, oldName string, newName string) error {
+	_, err := s.db.Exec(fmt.Sprintf("ALTER INDEX %v RENAME TO %v", s.Quote(oldName), s.Quote(newName)))
+	return err
+}
+
+func (s commonDialect) DropConstraint(tableName string, constraintName string) error {
+	_, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %v DROP CONSTRAINT %v", s.Quote(tableName), s.Quote(constraintName)))
+	return err
+}
+
+func (s commonDialect) GetTables() ([]string, error) {
+	rows, err := s.db.Query("SELECT table_name FROM INFORMATION_SCHEMA.TABLES WHERE table_schema = ? AND table_type = 'BASE TABLE' ORDER BY table_name", s.CurrentDatabase())
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (s commonDialect) ColumnTypes(tableName string) (columnTypes []ColumnType, err error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	rows, err := s.db.Query(`SELECT c.column_name, c.data_type, c.is_nullable, c.column_default, c.character_maximum_length, c.numeric_precision, c.numeric_scale,
+	(SELECT count(*) FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name AND kcu.table_name = tc.table_name
+		WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema AND tc.table_name = c.table_name AND kcu.column_name = c.column_name)
+	FROM INFORMATION_SCHEMA.COLUMNS c WHERE c.table_schema = ? AND c.table_name = ? ORDER BY c.ordinal_position`, currentDatabase, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			columnType               ColumnType
+			nullable                 string
+			length, precision, scale sql.NullInt64
+			primaryKey               int
+		)
+
+		if err = rows.Scan(&columnType.Name, &columnType.DatabaseType, &nullable, &columnType.DefaultValue, &length, &precision, &scale, &primaryKey); err != nil {
+			return nil, err
+		}
+
+		if length.Valid && length.Int64 > 0 {
+			columnType.DatabaseType = fmt.Sprintf("%v(%d)", columnType.DatabaseType, length.Int64)
+			columnType.Length = length.Int64
+		}
+		columnType.Precision, columnType.Scale = precision.Int64, scale.Int64
+		columnType.Nullable = strings.EqualFold(nullable, "YES")
+		columnType.PrimaryKey = primaryKey > 0
+		columnTypes = append(columnTypes, columnType)
+	}
+	return columnTypes, rows.Err()
+}
+
+func (s commonDialect) GetIndexes(tableName string) ([]Index, error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	rows, err := s.db.Query("SELECT index_name, column_name, non_unique = 0, index_name = 'PRIMARY' FROM INFORMATION_SCHEMA.STATISTICS WHERE table_schema = ? AND table_name = ? ORDER BY index_name, seq_in_index", currentDatabase, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanIndexes(rows)
+}
+
+func (s commonDialect) GetForeignKeys(tableName string) ([]ForeignKey, error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	rows, err := s.db.Query(`SELECT rc.constraint_name, kcu.column_name, refkcu.table_name, refkcu.column_name, rc.update_rule, rc.delete_rule
+	FROM INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+	JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu ON kcu.constraint_schema = rc.constraint_schema AND kcu.constraint_name = rc.constraint_name
+	JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE refkcu ON refkcu.constraint_schema = rc.unique_constraint_schema AND refkcu.constraint_name = rc.unique_constraint_name AND refkcu.ordinal_position = kcu.position_in_unique_constraint
+	WHERE kcu.table_schema = ? AND kcu.table_name = ? ORDER BY rc.constraint_name, kcu.ordinal_position`, currentDatabase, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(rows)
+}
+
 func (s commonDialect) CurrentDatabase() (name string) {
 	s.db.QueryRow("SELECT DATABASE()").Scan(&name)
 	return
@@ -2,6 +2,7 @@ package gorm
 
 import (
 	"crypto/sha1"
+	"database/sql"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -137,6 +138,128 @@ func (s mysql) ModifyColumn(tableName string, columnName string, typ string) err
 	return err
 }
 
+func (s *mysql) AlterColumn(tableName string, field *StructField) error {
+	return alterColumn(s.db, s, tableName, field)
+}
+
+func (s mysql) RenameIndex(tableName string, oldName string, newName string) error {
+	_, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %v RENAME INDEX %v TO %v", s.Quote(tableName), s.Quote(oldName), s.Quote(newName)))
+	return err
+}
+
+// RenameColumn mysql 5.7 doesn't support `RENAME COLUMN`, the column is changed with its definition from `SHOW CREATE TABLE`
+func (s mysql) RenameColumn(tableName string, oldName string, newName string) error {
+	var table, createSQL string
+	if err := s.db.QueryRow(fmt.Sprintf("SHOW CREATE TABLE %v", s.Quote(tableName))).Scan(&table, &createSQL); err != nil {
+		return err
+	}
+
+	prefix := s.Quote(oldName) + " "
+	for _, line := range strings.Split(createSQL, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, prefix) {
+			definition := strings.TrimSuffix(strings.TrimPrefix(line, prefix), ",")
+			_, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %v CHANGE COLUMN %v %v %v", s.Quote(tableName), s.Quote(oldName), s.Quote(newName), definition))
+			return err
+		}
+	}
+	return fmt.Errorf("column %v not found", oldName)
+}
+
+// DropConstraint mysql drops constraints with statements of their types, e.g. `DROP FOREIGN KEY` and `DROP CHECK`
+func (s mysql) DropConstraint(tableName string, constraintName string) error {
+	var constraintType string
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	s.db.QueryRow("SELECT constraint_type FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS WHERE constraint_schema = ? AND table_name = ? AND constraint_name = ?", currentDatabase, tableName, constraintName).Scan(&constraintType)
+
+	query := "ALTER TABLE %v DROP CONSTRAINT %v"
+	switch constraintType {
+	case "FOREIGN KEY":
+		query = "ALTER TABLE %v DROP FOREIGN KEY %v"
+	case "CHECK":
+		query = "ALTER TABLE %v DROP CHECK %v"
+	case "UNIQUE":
+		query = "ALTER TABLE %v DROP INDEX %v"
+	}
+	_, err := s.db.Exec(fmt.Sprintf(query, s.Quote(tableName), s.Quote(constraintName)))
+	return err
+}
+
+func (s mysql) ColumnTypes(tableName string) (columnTypes []ColumnType, err error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	rows, err := s.db.Query("SELECT column_name, column_type, is_nullable, column_default, character_maximum_length, numeric_precision, numeric_scale, column_key, extra, column_comment FROM INFORMATION_SCHEMA.COLUMNS WHERE table_schema = ? AND table_name = ? ORDER BY ordinal_position", currentDatabase, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			columnType                 ColumnType
+			nullable, columnKey, extra string
+			length, precision, scale   sql.NullInt64
+		)
+
+		if err = rows.Scan(&columnType.Name, &columnType.DatabaseType, &nullable, &columnType.DefaultValue, &length, &precision, &scale, &columnKey, &extra, &columnType.Comment); err != nil {
+			return nil, err
+		}
+		columnType.Nullable = strings.EqualFold(nullable, "YES")
+		columnType.Length, columnType.Precision, columnType.Scale = length.Int64, precision.Int64, scale.Int64
+		columnType.PrimaryKey = columnKey == "PRI"
+		columnType.AutoIncrement = strings.Contains(strings.ToLower(extra), "auto_increment")
+		columnTypes = append(columnTypes, columnType)
+	}
+	return columnTypes, rows.Err()
+}
+
+// GetForeignKeys mysql records referenced columns in `KEY_COLUMN_USAGE`, as names of referenced unique constraints aren't unique in a database
+func (s mysql) GetForeignKeys(tableName string) ([]ForeignKey, error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	rows, err := s.db.Query(`SELECT kcu.constraint_name, kcu.column_name, kcu.referenced_table_name, kcu.referenced_column_name, rc.update_rule, rc.delete_rule
+	FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+	JOIN INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc ON rc.constraint_schema = kcu.constraint_schema AND rc.constraint_name = kcu.constraint_name AND rc.table_name = kcu.table_name
+	WHERE kcu.table_schema = ? AND kcu.table_name = ? AND kcu.referenced_table_name IS NOT NULL ORDER BY kcu.constraint_name, kcu.ordinal_position`, currentDatabase, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(rows)
+}
+
+// AlterColumnSQL mysql redefines the whole column with `MODIFY COLUMN`
+func (s mysql) AlterColumnSQL(kind SchemaChangeKind, tableName string, field *StructField) string {
+	return fmt.Sprintf("ALTER TABLE %v MODIFY COLUMN %v %v", s.Quote(tableName), s.Quote(field.DBName), s.DataTypeOf(field))
+}
+
+// DropIndexSQL mysql drops indexes with `DROP INDEX name ON table`
+func (s mysql) DropIndexSQL(tableName string, indexName string) string {
+	return fmt.Sprintf("DROP INDEX %v ON %v", indexName, s.Quote(tableName))
+}
+
+// CreateIndexSQL mysql supports prefix lengths, `USING` before `ON` and comments, but not partial indexes
+func (s mysql) CreateIndexSQL(quotedTableName string, index Index) ([]string, error) {
+	if index.Where != "" {
+		return nil, ErrPartialIndexUnsupported
+	}
+
+	sql := "CREATE INDEX"
+	if index.Unique {
+		sql = "CREATE UNIQUE INDEX"
+	}
+
+	sql += " " + index.Name
+	if index.Type != "" {
+		sql += " USING " + index.Type
+	}
+
+	sql += fmt.Sprintf(" ON %v(%v)", quotedTableName, strings.Join(indexColumnsSQL(index, true), ", "))
+	if index.Comment != "" {
+		sql += " COMMENT " + quoteString(index.Comment)
+	}
+	if index.Option != "" {
+		sql += " " + index.Option
+	}
+	return []string{sql}, nil
+}
+
 func (s mysql) LimitAndOffsetSQL(limit, offset interface{}) (sql string) {
 	if limit != nil {
 		if parsedLimit, err := strconv.ParseInt(fmt.Sprint(limit), 0, 0); err == nil && parsedLimit >= 0 {
@@ -168,6 +291,16 @@ func (mysql) SelectFromDummyTable() string {
 	return "FROM DUAL"
 }
 
+// MaxKeyNameLength mysql limits identifiers to 64 characters
+func (mysql) MaxKeyNameLength() int {
+	return 64
+}
+
+// SupportTransactionalDDL mysql commits transactions implicitly before DDL statements
+func (mysql) SupportTransactionalDDL() bool {
+	return false
+}
+
 func (s mysql) BuildKeyName(kind, tableName string, fields ...string) string {
 	keyName := s.commonDialect.BuildKeyName(kind, tableName, fields...)
 	if utf8.RuneCountInString(keyName) <= 64 {
@@ -186,6 +319,55 @@ func (s mysql) BuildKeyName(kind, tableName string, fields ...string) string {
 	return fmt.Sprintf("%s%x", string(destRunes), bs)
 }
 
+func (mysql) MaxBindVars() int {
+	return 65535
+}
+
 func (mysql) DefaultValueStr() string {
 	return "VALUES()"
 }
+
+// UpsertSQL insert rows with `ON DUPLICATE KEY UPDATE`, mysql resolves conflicts of any unique key, so the conflict target is
+// only used to build a no-op assignment when conflicted rows should be skipped
+func (mysql) UpsertSQL(tableName string, columns []string, rows []string, output string, onConflict OnConflict) (string, error) {
+	var assignments []string
+	if onConflict.DoNothing || len(onConflict.DoUpdates) == 0 {
+		column := columns[0]
+		if len(onConflict.Columns) > 0 {
+			column = onConflict.Columns[0]
+		}
+		assignments = append(assignments, fmt.Sprintf("%v = %v", column, column))
+	} else {
+		for _, column := range onConflict.DoUpdates {
+			assignments = append(assignments, fmt.Sprintf("%v = VALUES(%v)", column, column))
+		}
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %v (%v)%v VALUES %v ON DUPLICATE KEY UPDATE %v",
+		tableName,
+		strings.Join(columns, ","),
+		addExtraSpaceIfExist(output),
+		strings.Join(rows, ","),
+		strings.Join(assignments, ","),
+	), nil
+}
+
+var mysqlErrorNumberRegexp = regexp.MustCompile(`^Error (\d+)`)
+
+// TranslateError translate errors by their numbers, which are formatted as `Error 1062 (23000): ...` by the mysql driver
+func (mysql) TranslateError(err error) error {
+	if matches := mysqlErrorNumberRegexp.FindStringSubmatch(err.Error()); len(matches) > 1 {
+		switch matches[1] {
+		case "1062":
+			return ErrDuplicatedKey
+		case "1451", "1452":
+			return ErrForeignKeyViolated
+		case "3819":
+			return ErrCheckConstraintViolated
+		case "1048", "1364":
+			return ErrNotNullViolated
+		}
+	}
+	return nil
+}
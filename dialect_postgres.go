@@ -2,6 +2,7 @@ package gorm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -115,6 +116,78 @@ func (s postgres) HasColumn(tableName string, columnName string) bool {
 	return count > 0
 }
 
+func (s *postgres) AlterColumn(tableName string, field *StructField) error {
+	return alterColumn(s.db, s, tableName, field)
+}
+
+func (s postgres) GetTables() ([]string, error) {
+	rows, err := s.db.Query("SELECT table_name FROM INFORMATION_SCHEMA.tables WHERE table_type = 'BASE TABLE' AND table_schema = CURRENT_SCHEMA() ORDER BY table_name")
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (s postgres) ColumnTypes(tableName string) (columnTypes []ColumnType, err error) {
+	rows, err := s.db.Query(`SELECT a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, pg_get_expr(d.adbin, d.adrelid),
+	EXISTS (SELECT 1 FROM pg_index ix WHERE ix.indrelid = a.attrelid AND ix.indisprimary AND a.attnum = ANY(ix.indkey)),
+	a.attidentity <> '' OR COALESCE(pg_get_expr(d.adbin, d.adrelid) LIKE 'nextval(%', false), COALESCE(col_description(a.attrelid, a.attnum), '')
+FROM pg_attribute a JOIN pg_class c ON c.oid = a.attrelid JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE c.relname = $1 AND n.nspname = CURRENT_SCHEMA() AND a.attnum > 0 AND NOT a.attisdropped ORDER BY a.attnum`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var columnType ColumnType
+		if err = rows.Scan(&columnType.Name, &columnType.DatabaseType, &columnType.Nullable, &columnType.DefaultValue, &columnType.PrimaryKey, &columnType.AutoIncrement, &columnType.Comment); err != nil {
+			return nil, err
+		}
+		parseDataTypeArgs(&columnType)
+		columnTypes = append(columnTypes, columnType)
+	}
+	return columnTypes, rows.Err()
+}
+
+// GetForeignKeys postgres stores actions of foreign keys as codes, `a` for `NO ACTION`, `r` for `RESTRICT` and so on
+func (s postgres) GetForeignKeys(tableName string) ([]ForeignKey, error) {
+	rows, err := s.db.Query(`SELECT con.conname, a.attname, rt.relname, ra.attname,
+	CASE con.confupdtype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END,
+	CASE con.confdeltype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END
+FROM pg_constraint con JOIN pg_class t ON t.oid = con.conrelid JOIN pg_namespace n ON n.oid = t.relnamespace JOIN pg_class rt ON rt.oid = con.confrelid
+JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord) ON true
+JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+WHERE con.contype = 'f' AND t.relname = $1 AND n.nspname = CURRENT_SCHEMA()
+ORDER BY con.conname, k.ord`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanForeignKeys(rows)
+}
+
+func (s postgres) GetIndexes(tableName string) ([]Index, error) {
+	rows, err := s.db.Query(`SELECT i.relname, a.attname, ix.indisunique, ix.indisprimary
+FROM pg_index ix JOIN pg_class t ON t.oid = ix.indrelid JOIN pg_class i ON i.oid = ix.indexrelid JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE t.relname = $1 AND n.nspname = CURRENT_SCHEMA() AND (ix.indisprimary OR NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = ix.indexrelid))
+ORDER BY i.relname, k.ord`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return scanIndexes(rows)
+}
+
+// CreateIndexSQL postgres sets comments of indexes with `COMMENT ON INDEX`
+func (s postgres) CreateIndexSQL(quotedTableName string, index Index) ([]string, error) {
+	sqls := []string{defaultCreateIndexSQL(quotedTableName, index)}
+	if index.Comment != "" {
+		sqls = append(sqls, fmt.Sprintf("COMMENT ON INDEX %v IS %v", index.Name, quoteString(index.Comment)))
+	}
+	return sqls, nil
+}
+
 func (s postgres) CurrentDatabase() (name string) {
 	s.db.QueryRow("SELECT CURRENT_DATABASE()").Scan(&name)
 	return
@@ -124,10 +197,36 @@ func (s postgres) LastInsertIDReturningSuffix(tableName, key string) string {
 	return fmt.Sprintf("RETURNING %v.%v", tableName, key)
 }
 
+func (postgres) MaxBindVars() int {
+	return 65535
+}
+
 func (postgres) SupportLastInsertID() bool {
 	return false
 }
 
+// TranslateError translate errors by their SQLSTATE codes, which are returned by `SQLState()` of pgx or `Get('C')` of pq
+func (postgres) TranslateError(err error) error {
+	var code string
+	if pgErr := (interface{ SQLState() string })(nil); errors.As(err, &pgErr) {
+		code = pgErr.SQLState()
+	} else if pqErr := (interface{ Get(byte) string })(nil); errors.As(err, &pqErr) {
+		code = pqErr.Get('C')
+	}
+
+	switch code {
+	case "23505":
+		return ErrDuplicatedKey
+	case "23503":
+		return ErrForeignKeyViolated
+	case "23514":
+		return ErrCheckConstraintViolated
+	case "23502":
+		return ErrNotNullViolated
+	}
+	return nil
+}
+
 func isUUID(value reflect.Value) bool {
 	if value.Kind() != reflect.Array || value.Type().Len() != 16 {
 		return false
@@ -1,8 +1,12 @@
 package gorm
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -72,7 +76,7 @@ func (s *sqlite3) DataTypeOf(field *StructField) string {
 
 func (s sqlite3) HasIndex(tableName string, indexName string) bool {
 	var count int
-	s.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM sqlite_master WHERE tbl_name = ? AND sql LIKE '%%INDEX %v ON%%'", indexName), tableName).Scan(&count)
+	s.db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND name = ?", tableName, indexName).Scan(&count)
 	return count > 0
 }
 
@@ -88,6 +92,410 @@ func (s sqlite3) HasColumn(tableName string, columnName string) bool {
 	return count > 0
 }
 
+// ModifyColumn sqlite can't alter columns, the table is rebuilt with the new definition of the column
+func (s sqlite3) ModifyColumn(tableName string, columnName string, typ string) error {
+	columnName = strings.Trim(columnName, "\"`")
+	return s.rebuildTable(strings.Trim(tableName, "\"`"), func(definitions []string) ([]string, error) {
+		for idx, definition := range definitions {
+			if name, isColumn := sqliteDefinitionName(definition); isColumn && name == columnName {
+				definitions[idx] = fmt.Sprintf("%v %v", s.Quote(columnName), typ)
+				return definitions, nil
+			}
+		}
+		return nil, fmt.Errorf("column %v not found", columnName)
+	})
+}
+
+// AlterColumnSQL sqlite can't alter columns with statements, planned changes of columns are applied by AlterColumn
+func (s sqlite3) AlterColumnSQL(kind SchemaChangeKind, tableName string, field *StructField) string {
+	return ""
+}
+
+// AlterColumn sqlite rebuilds the table with the definition of the field
+func (s *sqlite3) AlterColumn(tableName string, field *StructField) error {
+	return s.ModifyColumn(tableName, field.DBName, s.DataTypeOf(field))
+}
+
+// RenameIndex sqlite can't rename indexes, the index is dropped and created again with the new name
+func (s sqlite3) RenameIndex(tableName string, oldName string, newName string) error {
+	var createSQL string
+	if err := s.db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND name = ?", tableName, oldName).Scan(&createSQL); err != nil {
+		return err
+	}
+
+	loc := sqliteIndexNameRegexp.FindStringSubmatchIndex(createSQL)
+	if loc == nil {
+		return fmt.Errorf("failed to parse index %v", oldName)
+	}
+
+	// keep `UNIQUE` and `IF NOT EXISTS`, only replace the name
+	return s.execSQLs([]string{
+		fmt.Sprintf("DROP INDEX %v", s.Quote(oldName)),
+		createSQL[:loc[3]] + s.Quote(newName) + createSQL[loc[1]:],
+	})
+}
+
+// HasForeignKey sqlite only knows names of foreign keys declared with `CONSTRAINT name`
+func (s sqlite3) HasForeignKey(tableName string, foreignKeyName string) bool {
+	foreignKeys, _ := s.GetForeignKeys(tableName)
+	for _, foreignKey := range foreignKeys {
+		if foreignKey.Name == foreignKeyName {
+			return true
+		}
+	}
+	return false
+}
+
+// AddConstraint sqlite can't add constraints, the table is rebuilt with the constraint
+func (s sqlite3) AddConstraint(tableName string, definition string) error {
+	return s.rebuildTable(tableName, func(definitions []string) ([]string, error) {
+		return append(definitions, definition), nil
+	})
+}
+
+// DropConstraint sqlite can't drop constraints, the table is rebuilt without the constraint
+func (s sqlite3) DropConstraint(tableName string, constraintName string) error {
+	return s.rebuildTable(tableName, func(definitions []string) ([]string, error) {
+		for idx, definition := range definitions {
+			if name, isColumn := sqliteDefinitionName(definition); !isColumn && strings.EqualFold(name, constraintName) {
+				return append(definitions[:idx], definitions[idx+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("constraint %v not found", constraintName)
+	})
+}
+
+// rebuildTable create a new table with definitions of columns and constraints changed by fc, copy rows to it, then replace the old table
+func (s sqlite3) rebuildTable(tableName string, fc func(definitions []string) ([]string, error)) error {
+	var createSQL string
+	if err := s.db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).Scan(&createSQL); err != nil {
+		return err
+	}
+
+	start, end := strings.Index(createSQL, "("), strings.LastIndex(createSQL, ")")
+	if start < 0 || end < start {
+		return fmt.Errorf("failed to parse table %v", tableName)
+	}
+
+	definitions, err := fc(splitSQLDefinitions(createSQL[start+1 : end]))
+	if err != nil {
+		return err
+	}
+
+	columnTypes, err := s.ColumnTypes(tableName)
+	if err != nil {
+		return err
+	}
+
+	var columns []string
+	for _, columnType := range columnTypes {
+		for _, definition := range definitions {
+			if name, isColumn := sqliteDefinitionName(definition); isColumn && name == columnType.Name {
+				columns = append(columns, s.Quote(name))
+				break
+			}
+		}
+	}
+
+	// indexes are dropped with the table, create them again
+	rows, err := s.db.Query("SELECT sql FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL", tableName)
+	if err != nil {
+		return err
+	}
+
+	indexSQLs, err := scanStrings(rows)
+	if err != nil {
+		return err
+	}
+
+	var (
+		quotedTable    = s.Quote(tableName)
+		quotedNewTable = s.Quote(tableName + "__temp")
+	)
+
+	return s.execSQLs(append([]string{
+		fmt.Sprintf("CREATE TABLE %v (%v)%v", quotedNewTable, strings.Join(definitions, ", "), createSQL[end+1:]),
+		fmt.Sprintf("INSERT INTO %v (%v) SELECT %v FROM %v", quotedNewTable, strings.Join(columns, ", "), strings.Join(columns, ", "), quotedTable),
+		fmt.Sprintf("DROP TABLE %v", quotedTable),
+		fmt.Sprintf("ALTER TABLE %v RENAME TO %v", quotedNewTable, quotedTable),
+	}, indexSQLs...))
+}
+
+// execSQLs execute statements in a transaction with foreign keys disabled, as dropping tables deletes or updates rows referencing them.
+// `PRAGMA foreign_keys` only affects its connection and is a no-op in transactions, so it is disabled on a dedicated connection
+// before beginning the transaction, statements are refused in the caller's transaction if foreign keys are enabled
+func (s sqlite3) execSQLs(sqls []string) error {
+	var (
+		ctx         = context.Background()
+		conn        = s.db
+		foreignKeys int
+	)
+
+	if db, ok := conn.(*preparedStmtDB); ok {
+		conn = db.SQLCommon
+	}
+
+	db, ok := conn.(*sql.DB)
+	if !ok {
+		queryRowContext(ctx, conn, "PRAGMA foreign_keys").Scan(&foreignKeys)
+		if foreignKeys == 1 {
+			return errors.New("sqlite can't rebuild tables in transactions with foreign keys enabled, dropping the table would change rows referencing it")
+		}
+		return execSQLs(ctx, conn, sqls)
+	}
+
+	dbConn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	dbConn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys)
+	if foreignKeys == 1 {
+		if _, err := dbConn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return err
+		}
+		defer dbConn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+
+	tx, err := dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := execSQLs(ctx, tx, sqls); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func execSQLs(ctx context.Context, db SQLCommon, sqls []string) error {
+	for _, query := range sqls {
+		if _, err := execContext(ctx, db, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteIndexNameRegexp match `CREATE INDEX` statements until the name of the index, the first group is the part before the name
+var sqliteIndexNameRegexp = regexp.MustCompile("(?i)^(CREATE\\s+(?:UNIQUE\\s+)?INDEX\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?)(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|\\S+)")
+
+// splitSQLDefinitions split definitions of columns and constraints of `CREATE TABLE` statements by commas outside of parentheses and quotes
+func splitSQLDefinitions(body string) (definitions []string) {
+	var (
+		depth int
+		quote rune
+		start int
+	)
+
+	for idx, r := range body {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '[':
+			quote = ']'
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			definitions = append(definitions, strings.TrimSpace(body[start:idx]))
+			start = idx + 1
+		}
+	}
+	return append(definitions, strings.TrimSpace(body[start:]))
+}
+
+// sqliteDefinitionName return the column name of a column definition, or the name of a named table constraint
+func sqliteDefinitionName(definition string) (name string, isColumn bool) {
+	fields := strings.Fields(definition)
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	switch strings.ToUpper(fields[0]) {
+	case "CONSTRAINT":
+		if len(fields) > 1 {
+			name = strings.Trim(fields[1], "\"`[]")
+		}
+		return name, false
+	case "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+		return "", false
+	}
+	return strings.Trim(fields[0], "\"`[]"), true
+}
+
+func (s sqlite3) GetTables() ([]string, error) {
+	rows, err := s.db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	return scanStrings(rows)
+}
+
+func (s sqlite3) ColumnTypes(tableName string) (columnTypes []ColumnType, err error) {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%v)", s.Quote(tableName)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, primaryKey int
+			columnType               ColumnType
+		)
+
+		if err = rows.Scan(&cid, &columnType.Name, &columnType.DatabaseType, &notNull, &columnType.DefaultValue, &primaryKey); err != nil {
+			return nil, err
+		}
+		columnType.Nullable = notNull == 0
+		columnType.PrimaryKey = primaryKey > 0
+		parseDataTypeArgs(&columnType)
+		columnTypes = append(columnTypes, columnType)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// a single `INTEGER PRIMARY KEY` column is an alias of the rowid, which is auto incremented
+	var primaryKeys []int
+	for idx, columnType := range columnTypes {
+		if columnType.PrimaryKey {
+			primaryKeys = append(primaryKeys, idx)
+		}
+	}
+	if len(primaryKeys) == 1 && strings.EqualFold(columnTypes[primaryKeys[0]].DatabaseType, "integer") {
+		columnTypes[primaryKeys[0]].AutoIncrement = true
+	}
+	return columnTypes, nil
+}
+
+func (s sqlite3) GetIndexes(tableName string) (indexes []Index, err error) {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA index_list(%v)", s.Quote(tableName)))
+	if err != nil {
+		return nil, err
+	}
+
+	columns, _ := rows.Columns()
+	for rows.Next() {
+		var (
+			values = make([]interface{}, len(columns))
+			index  Index
+			origin string
+		)
+
+		// columns are seq, name, unique, origin and partial, the last two are missing in old versions
+		for idx := range values {
+			values[idx] = new(interface{})
+		}
+		values[1], values[2] = &index.Name, &index.Unique
+		if len(values) > 3 {
+			values[3] = &origin
+		}
+
+		if err = rows.Scan(values...); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		// skip indexes created by unique constraints
+		if origin != "u" {
+			index.Primary = origin == "pk"
+			indexes = append(indexes, index)
+		}
+	}
+	rows.Close()
+
+	for idx := range indexes {
+		infoRows, err := s.db.Query(fmt.Sprintf("PRAGMA index_info(%v)", s.Quote(indexes[idx].Name)))
+		if err != nil {
+			return nil, err
+		}
+
+		for infoRows.Next() {
+			var (
+				seqno, cid int
+				column     string
+			)
+			if err = infoRows.Scan(&seqno, &cid, &column); err != nil {
+				infoRows.Close()
+				return nil, err
+			}
+			indexes[idx].Columns = append(indexes[idx].Columns, column)
+		}
+		infoRows.Close()
+	}
+	return indexes, nil
+}
+
+// sqliteConstraintRegexp match names of foreign key constraints in `CREATE TABLE` statements, as `PRAGMA foreign_key_list` doesn't return them
+var sqliteConstraintRegexp = regexp.MustCompile("(?i)CONSTRAINT\\s+[\"`\\[]?(\\w+)[\"`\\]]?\\s+FOREIGN\\s+KEY\\s*\\(([^)]*)\\)")
+
+func (s sqlite3) GetForeignKeys(tableName string) (foreignKeys []ForeignKey, err error) {
+	var createSQL string
+	s.db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).Scan(&createSQL)
+
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA foreign_key_list(%v)", s.Quote(tableName)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var (
+			id, seq                                           int
+			referenceTable, column, onUpdate, onDelete, match string
+			referenceColumn                                   sql.NullString
+		)
+
+		if err = rows.Scan(&id, &seq, &referenceTable, &column, &referenceColumn, &onUpdate, &onDelete, &match); err != nil {
+			return nil, err
+		}
+
+		if len(ids) == 0 || ids[len(ids)-1] != id {
+			ids = append(ids, id)
+			foreignKeys = append(foreignKeys, ForeignKey{ReferenceTable: referenceTable, OnUpdate: onUpdate, OnDelete: onDelete})
+		}
+		foreignKey := &foreignKeys[len(foreignKeys)-1]
+		foreignKey.Columns = append(foreignKey.Columns, column)
+		foreignKey.ReferenceColumns = append(foreignKey.ReferenceColumns, referenceColumn.String)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, matches := range sqliteConstraintRegexp.FindAllStringSubmatch(createSQL, -1) {
+		var columns []string
+		for _, column := range strings.Split(matches[2], ",") {
+			columns = append(columns, strings.Trim(strings.TrimSpace(column), "\"`[]"))
+		}
+
+		for idx := range foreignKeys {
+			if strings.EqualFold(strings.Join(foreignKeys[idx].Columns, ","), strings.Join(columns, ",")) && foreignKeys[idx].Name == "" {
+				foreignKeys[idx].Name = matches[1]
+				break
+			}
+		}
+	}
+	return foreignKeys, nil
+}
+
+// CreateIndexSQL sqlite has no index methods and options
+func (s sqlite3) CreateIndexSQL(quotedTableName string, index Index) ([]string, error) {
+	index.Type, index.Option = "", ""
+	return []string{defaultCreateIndexSQL(quotedTableName, index)}, nil
+}
+
 func (s sqlite3) CurrentDatabase() (name string) {
 	var (
 		ifaces   = make([]interface{}, 3)
@@ -105,3 +513,32 @@ func (s sqlite3) CurrentDatabase() (name string) {
 	}
 	return
 }
+
+// MaxBindVars default SQLITE_MAX_VARIABLE_NUMBER of sqlite before 3.32.0
+func (sqlite3) MaxBindVars() int {
+	return 999
+}
+
+func (sqlite3) lastInsertIDOfLastRow() bool {
+	return true
+}
+
+// LockingSQL sqlite locks the whole database when writing, so row locking clauses are ignored
+func (sqlite3) LockingSQL(locking Locking) (string, string) {
+	return "", ""
+}
+
+// TranslateError translate constraint errors by sqlite's messages, as the driver isn't imported here
+func (sqlite3) TranslateError(err error) error {
+	switch message := err.Error(); {
+	case strings.HasPrefix(message, "UNIQUE constraint failed"), strings.HasPrefix(message, "PRIMARY KEY constraint failed"):
+		return ErrDuplicatedKey
+	case strings.HasPrefix(message, "FOREIGN KEY constraint failed"):
+		return ErrForeignKeyViolated
+	case strings.HasPrefix(message, "CHECK constraint failed"):
+		return ErrCheckConstraintViolated
+	case strings.HasPrefix(message, "NOT NULL constraint failed"):
+		return ErrNotNullViolated
+	}
+	return nil
+}
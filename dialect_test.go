@@ -0,0 +1,335 @@
+package gorm
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpsertSQL(t *testing.T) {
+	var (
+		columns = []string{`"email"`, `"name"`, `"age"`}
+		rows    = []string{"($1,$2,$3)", "($4,$5,$6)"}
+	)
+
+	tests := []struct {
+		dialect    Dialect
+		onConflict OnConflict
+		sql        string
+	}{
+		{
+			dialect:    &postgres{},
+			onConflict: OnConflict{Columns: []string{`"email"`}, DoUpdates: []string{`"name"`, `"age"`}},
+			sql:        `INSERT INTO "users" ("email","name","age") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name","age" = EXCLUDED."age"`,
+		},
+		{
+			dialect:    &postgres{},
+			onConflict: OnConflict{Columns: []string{`"email"`}, DoNothing: true},
+			sql:        `INSERT INTO "users" ("email","name","age") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT ("email") DO NOTHING`,
+		},
+		{
+			dialect:    &sqlite3{},
+			onConflict: OnConflict{Columns: []string{`"email"`}, DoUpdates: []string{`"name"`}},
+			sql:        `INSERT INTO "users" ("email","name","age") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name"`,
+		},
+		{
+			dialect:    &sqlite3{},
+			onConflict: OnConflict{DoNothing: true},
+			sql:        `INSERT INTO "users" ("email","name","age") VALUES ($1,$2,$3),($4,$5,$6) ON CONFLICT DO NOTHING`,
+		},
+		{
+			dialect:    &mysql{},
+			onConflict: OnConflict{Columns: []string{`"email"`}, DoUpdates: []string{`"name"`, `"age"`}},
+			sql:        `INSERT INTO "users" ("email","name","age") VALUES ($1,$2,$3),($4,$5,$6) ON DUPLICATE KEY UPDATE "name" = VALUES("name"),"age" = VALUES("age")`,
+		},
+		{
+			dialect:    &mysql{},
+			onConflict: OnConflict{Columns: []string{`"email"`}, DoNothing: true},
+			sql:        `INSERT INTO "users" ("email","name","age") VALUES ($1,$2,$3),($4,$5,$6) ON DUPLICATE KEY UPDATE "email" = "email"`,
+		},
+	}
+
+	for _, test := range tests {
+		if sql, err := upsertSQL(test.dialect, `"users"`, columns, rows, "", test.onConflict); err != nil || sql != test.sql {
+			t.Errorf("%v: expects upsert SQL %v, but got %v, %v", test.dialect.GetName(), test.sql, sql, err)
+		}
+	}
+}
+
+type pqError map[byte]string
+
+func (err pqError) Get(k byte) string {
+	return err[k]
+}
+
+func (err pqError) Error() string {
+	return err['M']
+}
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		dialect ErrorTranslatorDialect
+		err     error
+		expects error
+	}{
+		{&postgres{}, pqError{'C': "23505", 'M': "duplicate key value violates unique constraint"}, ErrDuplicatedKey},
+		{&postgres{}, pqError{'C': "23503"}, ErrForeignKeyViolated},
+		{&postgres{}, pqError{'C': "23514"}, ErrCheckConstraintViolated},
+		{&postgres{}, pqError{'C': "23502"}, ErrNotNullViolated},
+		{&postgres{}, pqError{'C': "42P01"}, nil},
+		{&mysql{}, errors.New("Error 1062 (23000): Duplicate entry 'jinzhu' for key 'name'"), ErrDuplicatedKey},
+		{&mysql{}, errors.New("Error 1452: Cannot add or update a child row"), ErrForeignKeyViolated},
+		{&mysql{}, errors.New("Error 3819 (HY000): Check constraint 'age_checker' is violated."), ErrCheckConstraintViolated},
+		{&mysql{}, errors.New("Error 1048 (23000): Column 'name' cannot be null"), ErrNotNullViolated},
+		{&mysql{}, errors.New("Error 1146 (42S02): Table 'users' doesn't exist"), nil},
+		{&sqlite3{}, errors.New("UNIQUE constraint failed: users.name"), ErrDuplicatedKey},
+		{&sqlite3{}, errors.New("FOREIGN KEY constraint failed"), ErrForeignKeyViolated},
+		{&sqlite3{}, errors.New("CHECK constraint failed: age_checker"), ErrCheckConstraintViolated},
+		{&sqlite3{}, errors.New("NOT NULL constraint failed: users.name"), ErrNotNullViolated},
+		{&sqlite3{}, errors.New("no such table: users"), nil},
+	}
+
+	for _, test := range tests {
+		if err := test.dialect.TranslateError(test.err); err != test.expects {
+			t.Errorf("%v should be translated to %v, but got %v", test.err, test.expects, err)
+		}
+	}
+}
+
+func TestTranslatedErrorKeepsOriginalError(t *testing.T) {
+	original := pqError{'C': "23505", 'M': "duplicate key value violates unique constraint"}
+	err := translateError(&postgres{}, original)
+
+	if !errors.Is(err, ErrDuplicatedKey) || err.Error() != original.Error() {
+		t.Errorf("Error should be translated to ErrDuplicatedKey with original message, but got %v", err)
+	}
+
+	var pqErr pqError
+	if !errors.As(err, &pqErr) || pqErr['C'] != "23505" {
+		t.Errorf("Original error should be reachable with errors.As")
+	}
+
+	if translateError(&postgres{}, err) != err {
+		t.Errorf("Translated error should not be translated again")
+	}
+}
+
+func TestLockingSQL(t *testing.T) {
+	tests := []struct {
+		dialect   Dialect
+		locking   Locking
+		clause    string
+		tableHint string
+	}{
+		{&postgres{}, Locking{Strength: "UPDATE"}, "FOR UPDATE", ""},
+		{&postgres{}, Locking{Strength: "UPDATE", Options: "SKIP LOCKED", Of: "jobs"}, `FOR UPDATE OF "jobs" SKIP LOCKED`, ""},
+		{&postgres{}, Locking{Strength: "SHARE", Options: "NOWAIT"}, "FOR SHARE NOWAIT", ""},
+		{&mysql{}, Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}, "FOR UPDATE SKIP LOCKED", ""},
+		{&sqlite3{}, Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}, "", ""},
+	}
+
+	for _, test := range tests {
+		if clause, tableHint := lockingSQL(test.dialect, test.locking); clause != test.clause || tableHint != test.tableHint {
+			t.Errorf("%v: expects locking clause %q and table hint %q, but got %q, %q", test.dialect.GetName(), test.clause, test.tableHint, clause, tableHint)
+		}
+	}
+}
+
+func TestCreateIndexSQL(t *testing.T) {
+	index := Index{
+		Name:    "idx_users_name",
+		Columns: []string{`"last_name"`, `"first_name"`},
+		Sorts:   []string{"DESC", ""},
+		Lengths: []int{10, 0},
+		Type:    "btree",
+		Where:   `"deleted_at" IS NULL`,
+		Option:  "CONCURRENTLY",
+		Comment: "user's name",
+	}
+
+	tests := []struct {
+		dialect Dialect
+		sqls    []string
+	}{
+		{&postgres{}, []string{`CREATE INDEX CONCURRENTLY idx_users_name ON "users" USING btree("last_name" DESC, "first_name") WHERE "deleted_at" IS NULL`, `COMMENT ON INDEX idx_users_name IS 'user''s name'`}},
+		{&sqlite3{}, []string{`CREATE INDEX idx_users_name ON "users"("last_name" DESC, "first_name") WHERE "deleted_at" IS NULL`}},
+		{&commonDialect{}, []string{`CREATE INDEX CONCURRENTLY idx_users_name ON "users" USING btree("last_name" DESC, "first_name") WHERE "deleted_at" IS NULL`}},
+	}
+
+	for _, test := range tests {
+		if sqls, err := createIndexSQL(test.dialect, `"users"`, index); err != nil || strings.Join(sqls, ";") != strings.Join(test.sqls, ";") {
+			t.Errorf("%v: expects index SQL %v, but got %v, %v", test.dialect.GetName(), test.sqls, sqls, err)
+		}
+	}
+
+	// mysql has no partial indexes, the condition shouldn't be dropped silently
+	if _, err := createIndexSQL(&mysql{}, `"users"`, index); err != ErrPartialIndexUnsupported {
+		t.Errorf("mysql: expects ErrPartialIndexUnsupported for partial indexes, but got %v", err)
+	}
+
+	index.Where = ""
+	if sqls, err := createIndexSQL(&mysql{}, `"users"`, index); err != nil || strings.Join(sqls, ";") != `CREATE INDEX idx_users_name USING btree ON "users"("last_name"(10) DESC, "first_name") COMMENT 'user''s name' CONCURRENTLY` {
+		t.Errorf("mysql: expects index SQL with prefix lengths and comment, but got %v, %v", sqls, err)
+	}
+}
+
+func TestLiteralSQL(t *testing.T) {
+	var (
+		name    = "it's"
+		nilName *string
+	)
+
+	tests := []struct {
+		value   interface{}
+		literal string
+	}{
+		{"it's", `'it''s'`},
+		{&name, `'it''s'`},
+		{nilName, "NULL"},
+		{nil, "NULL"},
+		{[]byte("bytes"), `'bytes'`},
+		{10, "10"},
+		{1.5, "1.5"},
+		{true, "TRUE"},
+		{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), `'2020-01-02 03:04:05'`},
+		{sql.NullString{String: "valuer", Valid: true}, `'valuer'`},
+		{sql.NullString{}, "NULL"},
+	}
+
+	for _, test := range tests {
+		if literal := literalSQL(test.value); literal != test.literal {
+			t.Errorf("expects literal %v for %#v, but got %v", test.literal, test.value, literal)
+		}
+	}
+}
+
+func TestTransactionDialect(t *testing.T) {
+	var (
+		db, tx  = &sql.DB{}, &sql.Tx{}
+		sqlite  = &sqlite3{}
+		dialect = &mysql{}
+	)
+	sqlite.SetDB(db)
+	dialect.SetDB(db)
+
+	if clone, ok := transactionDialect(sqlite, tx).(*sqlite3); !ok || clone.db != tx || sqlite.db != db {
+		t.Errorf("sqlite should be cloned to run statements in the transaction, but got %#v", clone)
+	}
+
+	// other dialects, including custom ones that may not be pointers, are shared with the db
+	if clone := transactionDialect(dialect, tx); clone != nil {
+		t.Errorf("dialects other than sqlite should not be cloned, but got %#v", clone)
+	}
+}
+
+func TestLimitKeyName(t *testing.T) {
+	name := "idx_" + strings.Repeat("very_long_table_name_", 5) + "column"
+	if keyName := limitKeyName(&mysql{}, name); len(keyName) != 64 || !strings.HasPrefix(keyName, name[:24]) {
+		t.Errorf("key name should be shortened to 64 characters, but got %v", keyName)
+	}
+
+	if keyName := limitKeyName(&sqlite3{}, name); keyName != name {
+		t.Errorf("key name should not be shortened, but got %v", keyName)
+	}
+}
+
+func TestMysqlKeyNames(t *testing.T) {
+	db := &DB{dialect: &mysql{}}
+	db.parent = db
+	scope := &Scope{db: db}
+
+	// names of the default naming strategy should be the same as before
+	table := "very_long_table_name_for_pinning_mysql_key_names"
+	if name := scope.indexKeyName("idx", table, "very_long_column_name_of_index"); name != "very_long_column_name_of32835c7eb527335e7c9ac6e7376e71c5558566eb" {
+		t.Errorf("long index names should be shortened by mysql's BuildKeyName, but got %v", name)
+	}
+
+	if name := scope.relationshipFKName(table, "company_id", "companies(id)"); name != "companies_id_8513a985be875955646b036248c7654e446ad059" {
+		t.Errorf("long foreign key names should be shortened by mysql's BuildKeyName, but got %v", name)
+	}
+
+	if name := scope.indexKeyName("idx", "users", "name"); name != "idx_users_name" {
+		t.Errorf("short index names should not be changed, but got %v", name)
+	}
+
+	db.namingStrategy = DefaultNamingStrategy{TablePrefix: "app_"}
+	if name := scope.indexKeyName("uix", "app_users", "email"); name != "uix_app_users_email" {
+		t.Errorf("index names of the default naming strategy should be built by the dialect, but got %v", name)
+	}
+}
+
+// batchLimitedDialect limits batches like mssql
+type batchLimitedDialect struct {
+	commonDialect
+}
+
+func (batchLimitedDialect) MaxBindVars() int {
+	return 2100
+}
+
+func (batchLimitedDialect) MaxInsertRows() int {
+	return 1000
+}
+
+func TestBatchSize(t *testing.T) {
+	db := &DB{dialect: &batchLimitedDialect{}, values: map[string]interface{}{}}
+	db.parent = db
+
+	tests := []struct {
+		columns   int
+		batchSize int
+		size      int
+	}{
+		{columns: 1, size: 1000},
+		{columns: 3, size: 700},
+		{columns: 3, batchSize: 100, size: 100},
+		{columns: 1, batchSize: 5000, size: 1000},
+	}
+
+	for _, test := range tests {
+		scope := &Scope{db: db}
+		if test.batchSize > 0 {
+			scope = &Scope{db: db.Set("gorm:batch_size", test.batchSize)}
+		}
+
+		if size := scope.batchSize(test.columns); size != test.size {
+			t.Errorf("batch size of %v columns should be %v, but got %v", test.columns, test.size, size)
+		}
+	}
+}
+
+func TestAlterTableWithoutDialectSupport(t *testing.T) {
+	db := &DB{dialect: struct{ Dialect }{&commonDialect{}}}
+	db.parent = db
+
+	if err := db.RenameTable("users", "accounts").Error; !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Should get ErrNotSupported for dialects can't alter tables, but got %v", err)
+	}
+
+	if err := db.RenameIndex("users", "idx_name", "idx_user_name").Error; !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Should get ErrNotSupported for dialects can't alter tables, but got %v", err)
+	}
+}
+
+func TestMigratorWithoutIntrospection(t *testing.T) {
+	db := &DB{dialect: struct{ Dialect }{&commonDialect{}}}
+	db.parent = db
+
+	if _, err := db.Migrator().ColumnTypes("users"); err != ErrNotSupported {
+		t.Errorf("Should get ErrNotSupported for dialects without introspection, but got %v", err)
+	}
+
+	if _, err := db.Migrator().GetIndexes("users"); err != ErrNotSupported {
+		t.Errorf("Should get ErrNotSupported for dialects without introspection, but got %v", err)
+	}
+
+	if _, err := db.Migrator().GetTables(); err != ErrNotSupported {
+		t.Errorf("Should get ErrNotSupported for dialects without introspection, but got %v", err)
+	}
+
+	if _, err := db.Migrator().GetForeignKeys("users"); err != ErrNotSupported {
+		t.Errorf("Should get ErrNotSupported for dialects without introspection, but got %v", err)
+	}
+}
@@ -1,27 +1,46 @@
 package mssql
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
-	_ "github.com/denisenkom/go-mssqldb"
+	mssqldb "github.com/denisenkom/go-mssqldb"
 	"github.com/jinzhu/gorm"
 )
 
 func setIdentityInsert(scope *gorm.Scope) {
 	if scope.Dialect().GetName() == "mssql" {
-		for _, field := range scope.PrimaryFields() {
+		for _, field := range primaryFieldsOfElems(scope) {
 			if _, ok := field.TagSettings["AUTO_INCREMENT"]; ok && !field.IsBlank {
 				scope.NewDB().Exec(fmt.Sprintf("SET IDENTITY_INSERT %v ON", scope.TableName()))
 				scope.InstanceSet("mssql:identity_insert_on", true)
+				return
 			}
 		}
 	}
 }
 
+// primaryFieldsOfElems return primary fields of scope's value, or of every element when creating a slice
+func primaryFieldsOfElems(scope *gorm.Scope) (fields []*gorm.Field) {
+	if indirectValue := scope.IndirectValue(); indirectValue.Kind() == reflect.Slice || indirectValue.Kind() == reflect.Array {
+		for i := 0; i < indirectValue.Len(); i++ {
+			if elem := indirectValue.Index(i); elem.Kind() == reflect.Ptr || elem.CanAddr() {
+				if elem.Kind() != reflect.Ptr {
+					elem = elem.Addr()
+				}
+				fields = append(fields, scope.New(elem.Interface()).PrimaryFields()...)
+			}
+		}
+		return fields
+	}
+	return scope.PrimaryFields()
+}
+
 func turnOffIdentityInsert(scope *gorm.Scope) {
 	if scope.Dialect().GetName() == "mssql" {
 		if _, ok := scope.InstanceGet("mssql:identity_insert_on"); ok {
@@ -130,7 +149,9 @@ func (s mssql) RemoveIndex(tableName string, indexName string) error {
 }
 
 func (s mssql) HasForeignKey(tableName string, foreignKeyName string) bool {
-	return false
+	var count int
+	s.db.QueryRow("SELECT count(*) FROM sys.foreign_keys WHERE parent_object_id = OBJECT_ID(?) AND name = ?", tableName, foreignKeyName).Scan(&count)
+	return count > 0
 }
 
 func (s mssql) HasTable(tableName string) bool {
@@ -152,6 +173,205 @@ func (s mssql) ModifyColumn(tableName string, columnName string, typ string) err
 	return err
 }
 
+// RenameTable mssql renames objects with `sp_rename`
+func (s mssql) RenameTable(oldName string, newName string) error {
+	_, err := s.db.Exec("EXEC sp_rename ?, ?", oldName, newName)
+	return err
+}
+
+func (s mssql) RenameColumn(tableName string, oldName string, newName string) error {
+	_, err := s.db.Exec("EXEC sp_rename ?, ?, 'COLUMN'", tableName+"."+oldName, newName)
+	return err
+}
+
+func (s mssql) RenameIndex(tableName string, oldName string, newName string) error {
+	_, err := s.db.Exec("EXEC sp_rename ?, ?, 'INDEX'", tableName+"."+oldName, newName)
+	return err
+}
+
+// AlterColumn mssql changes the type and nullability with one statement, then the default value
+func (s *mssql) AlterColumn(tableName string, field *gorm.StructField) error {
+	for _, kind := range []gorm.SchemaChangeKind{gorm.AlterColumnTypeChange, gorm.AlterColumnDefaultChange} {
+		if _, err := s.db.Exec(s.AlterColumnSQL(kind, tableName, field)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s mssql) DropConstraint(tableName string, constraintName string) error {
+	_, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %v DROP CONSTRAINT %v", s.Quote(tableName), s.Quote(constraintName)))
+	return err
+}
+
+func (s mssql) GetTables() ([]string, error) {
+	rows, err := s.db.Query("SELECT table_name FROM INFORMATION_SCHEMA.tables WHERE table_catalog = ? AND table_type = 'BASE TABLE' ORDER BY table_name", s.CurrentDatabase())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err = rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+func (s mssql) ColumnTypes(tableName string) (columnTypes []gorm.ColumnType, err error) {
+	currentDatabase, tableName := currentDatabaseAndTable(&s, tableName)
+	rows, err := s.db.Query(`SELECT c.column_name, c.data_type, c.is_nullable, c.column_default, c.character_maximum_length, c.numeric_precision, c.numeric_scale,
+	COLUMNPROPERTY(OBJECT_ID(c.table_schema + '.' + c.table_name), c.column_name, 'IsIdentity'),
+	(SELECT count(*) FROM information_schema.table_constraints tc JOIN information_schema.key_column_usage kcu ON kcu.constraint_name = tc.constraint_name AND kcu.table_name = tc.table_name
+		WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_catalog = c.table_catalog AND tc.table_name = c.table_name AND kcu.column_name = c.column_name),
+	(SELECT CAST(ep.value AS nvarchar(max)) FROM sys.extended_properties ep WHERE ep.major_id = OBJECT_ID(c.table_schema + '.' + c.table_name) AND ep.minor_id = COLUMNPROPERTY(ep.major_id, c.column_name, 'ColumnId') AND ep.name = 'MS_Description')
+	FROM information_schema.columns c WHERE c.table_catalog = ? AND c.table_name = ? ORDER BY c.ordinal_position`, currentDatabase, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			columnType               gorm.ColumnType
+			nullable                 string
+			length, precision, scale sql.NullInt64
+			identity                 sql.NullInt64
+			primaryKey               int
+			comment                  sql.NullString
+		)
+
+		if err = rows.Scan(&columnType.Name, &columnType.DatabaseType, &nullable, &columnType.DefaultValue, &length, &precision, &scale, &identity, &primaryKey, &comment); err != nil {
+			return nil, err
+		}
+
+		if length.Valid && length.Int64 == -1 {
+			columnType.DatabaseType = fmt.Sprintf("%v(max)", columnType.DatabaseType)
+		} else if length.Valid && length.Int64 > 0 {
+			columnType.DatabaseType = fmt.Sprintf("%v(%d)", columnType.DatabaseType, length.Int64)
+			columnType.Length = length.Int64
+		}
+		columnType.Precision, columnType.Scale = precision.Int64, scale.Int64
+		columnType.Nullable = strings.EqualFold(nullable, "YES")
+		columnType.PrimaryKey = primaryKey > 0
+		columnType.AutoIncrement = identity.Int64 == 1
+		columnType.Comment = comment.String
+		columnTypes = append(columnTypes, columnType)
+	}
+	return columnTypes, rows.Err()
+}
+
+func (s mssql) GetIndexes(tableName string) (indexes []gorm.Index, err error) {
+	rows, err := s.db.Query(`SELECT i.name, c.name, i.is_unique, i.is_primary_key FROM sys.indexes i
+JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+WHERE i.object_id = OBJECT_ID(?) AND i.is_unique_constraint = 0 ORDER BY i.name, ic.key_ordinal`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name, column    string
+			unique, primary bool
+		)
+
+		if err = rows.Scan(&name, &column, &unique, &primary); err != nil {
+			return nil, err
+		}
+
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, gorm.Index{Name: name, Unique: unique, Primary: primary})
+		}
+		indexes[len(indexes)-1].Columns = append(indexes[len(indexes)-1].Columns, column)
+	}
+	return indexes, rows.Err()
+}
+
+func (s mssql) GetForeignKeys(tableName string) (foreignKeys []gorm.ForeignKey, err error) {
+	rows, err := s.db.Query(`SELECT fk.name, c.name, rt.name, rc.name, fk.update_referential_action_desc, fk.delete_referential_action_desc FROM sys.foreign_keys fk
+JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id JOIN sys.tables rt ON rt.object_id = fk.referenced_object_id
+JOIN sys.columns c ON c.object_id = fkc.parent_object_id AND c.column_id = fkc.parent_column_id JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+WHERE fk.parent_object_id = OBJECT_ID(?) ORDER BY fk.name, fkc.constraint_column_id`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, column, referenceTable, referenceColumn, onUpdate, onDelete string
+		if err = rows.Scan(&name, &column, &referenceTable, &referenceColumn, &onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+
+		if len(foreignKeys) == 0 || foreignKeys[len(foreignKeys)-1].Name != name {
+			// actions are described like `SET_NULL` and `NO_ACTION`
+			foreignKeys = append(foreignKeys, gorm.ForeignKey{Name: name, ReferenceTable: referenceTable, OnUpdate: strings.Replace(onUpdate, "_", " ", -1), OnDelete: strings.Replace(onDelete, "_", " ", -1)})
+		}
+		foreignKey := &foreignKeys[len(foreignKeys)-1]
+		foreignKey.Columns = append(foreignKey.Columns, column)
+		foreignKey.ReferenceColumns = append(foreignKey.ReferenceColumns, referenceColumn)
+	}
+	return foreignKeys, rows.Err()
+}
+
+// AlterColumnSQL mssql changes types and nullability with `ALTER COLUMN`, default values are constraints which are dropped and added again
+func (s mssql) AlterColumnSQL(kind gorm.SchemaChangeKind, tableName string, field *gorm.StructField) string {
+	if kind != gorm.AlterColumnDefaultChange {
+		_, _, _, additionalType := gorm.ParseFieldStructForDialect(field, &s)
+		nullable := " NULL"
+		if _, ok := field.TagSettings["NOT NULL"]; ok {
+			nullable = " NOT NULL"
+		}
+		return fmt.Sprintf("ALTER TABLE %v ALTER COLUMN %v %v%v", s.Quote(tableName), s.Quote(field.DBName), strings.TrimSpace(strings.TrimSuffix(s.DataTypeOf(field), additionalType)), nullable)
+	}
+
+	sql := fmt.Sprintf("DECLARE @name sysname; SELECT @name = dc.name FROM sys.default_constraints dc JOIN sys.columns c ON c.object_id = dc.parent_object_id AND c.column_id = dc.parent_column_id"+
+		" WHERE dc.parent_object_id = OBJECT_ID('%v') AND c.name = '%v'; IF @name IS NOT NULL EXEC('ALTER TABLE %v DROP CONSTRAINT ' + @name);", tableName, field.DBName, s.Quote(tableName))
+	if value, ok := field.TagSettings["DEFAULT"]; ok {
+		sql += fmt.Sprintf(" ALTER TABLE %v ADD DEFAULT %v FOR %v;", s.Quote(tableName), value, s.Quote(field.DBName))
+	}
+	return sql
+}
+
+// DropIndexSQL mssql drops indexes with `DROP INDEX name ON table`
+func (s mssql) DropIndexSQL(tableName string, indexName string) string {
+	return fmt.Sprintf("DROP INDEX %v ON %v", indexName, s.Quote(tableName))
+}
+
+// CreateIndexSQL mssql uses types `CLUSTERED` and `NONCLUSTERED` before `INDEX`, other index methods are ignored,
+// and options like `WITH (ONLINE = ON)` follow columns
+func (s mssql) CreateIndexSQL(quotedTableName string, index gorm.Index) ([]string, error) {
+	sql := "CREATE"
+	if index.Unique {
+		sql += " UNIQUE"
+	}
+	if indexType := strings.ToUpper(index.Type); indexType == "CLUSTERED" || indexType == "NONCLUSTERED" {
+		sql += " " + indexType
+	}
+
+	var columns []string
+	for idx, column := range index.Columns {
+		if idx < len(index.Sorts) && index.Sorts[idx] != "" {
+			column += " " + index.Sorts[idx]
+		}
+		columns = append(columns, column)
+	}
+
+	sql += fmt.Sprintf(" INDEX %v ON %v(%v)", index.Name, quotedTableName, strings.Join(columns, ", "))
+	if index.Where != "" {
+		sql += " WHERE " + index.Where
+	}
+	if index.Option != "" {
+		sql += " " + index.Option
+	}
+	return []string{sql}, nil
+}
+
 func (s mssql) CurrentDatabase() (name string) {
 	s.db.QueryRow("SELECT DB_NAME() AS [Current Database]").Scan(&name)
 	return
@@ -179,14 +399,130 @@ func (mssql) SelectFromDummyTable() string {
 	return ""
 }
 
+// LastInsertIDOutputInterstitial mssql returns inserted IDs with `OUTPUT`
+func (mssql) LastInsertIDOutputInterstitial(tableName, columnName string, columns []string) string {
+	if len(columns) == 0 {
+		// No OUTPUT to query
+		return ""
+	}
+	return fmt.Sprintf("OUTPUT Inserted.%v", columnName)
+}
+
 func (mssql) LastInsertIDReturningSuffix(tableName, columnName string) string {
 	return ""
 }
 
+// UpsertSQL insert rows with `MERGE`, rows are matched with existing records by the conflict target, which must be inserted columns
+func (mssql) UpsertSQL(tableName string, columns []string, rows []string, output string, onConflict gorm.OnConflict) (string, error) {
+	insertedColumns := map[string]bool{}
+	for _, column := range columns {
+		insertedColumns[column] = true
+	}
+
+	var conditions, values []string
+	for _, column := range onConflict.Columns {
+		if !insertedColumns[column] {
+			return "", fmt.Errorf("mssql can't match conflicted rows by %v as it isn't inserted, set the conflict target with OnConflict", column)
+		}
+		conditions = append(conditions, fmt.Sprintf("target.%v = excluded.%v", column, column))
+	}
+
+	if len(conditions) == 0 {
+		return "", errors.New("mssql can't match conflicted rows without conflict target, set it with OnConflict")
+	}
+	for _, column := range columns {
+		values = append(values, "excluded."+column)
+	}
+
+	var matched string
+	if !onConflict.DoNothing && len(onConflict.DoUpdates) > 0 {
+		var assignments []string
+		for _, column := range onConflict.DoUpdates {
+			assignments = append(assignments, fmt.Sprintf("%v = excluded.%v", column, column))
+		}
+		matched = fmt.Sprintf(" WHEN MATCHED THEN UPDATE SET %v", strings.Join(assignments, ","))
+	}
+
+	return fmt.Sprintf(
+		"MERGE INTO %v WITH (HOLDLOCK) AS target USING (VALUES %v) AS excluded (%v) ON %v%v WHEN NOT MATCHED THEN INSERT (%v) VALUES (%v)%v;",
+		tableName,
+		strings.Join(rows, ","),
+		strings.Join(columns, ","),
+		strings.Join(conditions, " AND "),
+		matched,
+		strings.Join(columns, ","),
+		strings.Join(values, ","),
+		addExtraSpaceIfExist(output),
+	), nil
+}
+
+// SupportRowValues sql server doesn't support row value comparisons like `(a, b) > (?, ?)`
+func (mssql) SupportRowValues() bool {
+	return false
+}
+
+// LockingSQL sql server locks rows with table hints, `SKIP LOCKED` is the same as `READPAST`
+func (mssql) LockingSQL(locking gorm.Locking) (string, string) {
+	hints := []string{"UPDLOCK", "ROWLOCK"}
+	if strings.ToUpper(locking.Strength) == "SHARE" {
+		hints = []string{"HOLDLOCK", "ROWLOCK"}
+	}
+
+	switch strings.ToUpper(locking.Options) {
+	case "NOWAIT":
+		hints = append(hints, "NOWAIT")
+	case "SKIP LOCKED":
+		hints = append(hints, "READPAST")
+	}
+	return "", fmt.Sprintf("WITH (%v)", strings.Join(hints, ", "))
+}
+
+// TranslateError translate errors by their numbers, constraint conflicts share the same number, distinguished by messages
+func (mssql) TranslateError(err error) error {
+	var mssqlErr mssqldb.Error
+	if errors.As(err, &mssqlErr) {
+		switch mssqlErr.Number {
+		case 2601, 2627:
+			return gorm.ErrDuplicatedKey
+		case 547:
+			if strings.Contains(mssqlErr.Message, "CHECK constraint") {
+				return gorm.ErrCheckConstraintViolated
+			}
+			return gorm.ErrForeignKeyViolated
+		case 515:
+			return gorm.ErrNotNullViolated
+		}
+	}
+	return nil
+}
+
+// MaxBindVars sql server supports at most 2100 parameters in a request
+func (mssql) MaxBindVars() int {
+	return 2100
+}
+
+// MaxInsertRows sql server supports at most 1000 rows in a VALUES clause
+func (mssql) MaxInsertRows() int {
+	return 1000
+}
+
 func (mssql) DefaultValueStr() string {
 	return "DEFAULT VALUES"
 }
 
+func (mssql) SavePointSQL(name string) string {
+	return fmt.Sprintf("SAVE TRANSACTION %v", name)
+}
+
+func (mssql) RollbackToSavePointSQL(name string) string {
+	return fmt.Sprintf("ROLLBACK TRANSACTION %v", name)
+}
+
+// ReleaseSavePointSQL mssql has no statement releasing savepoints, they are released with the transaction
+func (mssql) ReleaseSavePointSQL(name string) string {
+	return ""
+}
+
 func currentDatabaseAndTable(dialect gorm.Dialect, tableName string) (string, string) {
 	if strings.Contains(tableName, ".") {
 		splitStrings := strings.SplitN(tableName, ".", 2)
@@ -194,3 +530,10 @@ func currentDatabaseAndTable(dialect gorm.Dialect, tableName string) (string, st
 	}
 	return dialect.CurrentDatabase(), tableName
 }
+
+func addExtraSpaceIfExist(str string) string {
+	if str != "" {
+		return " " + str
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package mssql
+
+import (
+	"testing"
+
+	mssqldb "github.com/denisenkom/go-mssqldb"
+	"github.com/jinzhu/gorm"
+)
+
+func TestUpsertSQL(t *testing.T) {
+	var (
+		columns = []string{"[email]", "[name]"}
+		rows    = []string{"($$$,$$$)", "($$$,$$$)"}
+	)
+
+	tests := []struct {
+		onConflict gorm.OnConflict
+		output     string
+		sql        string
+	}{
+		{
+			onConflict: gorm.OnConflict{Columns: []string{"[email]"}, DoUpdates: []string{"[name]"}},
+			output:     "OUTPUT Inserted.[id]",
+			sql: "MERGE INTO [users] WITH (HOLDLOCK) AS target USING (VALUES ($$$,$$$),($$$,$$$)) AS excluded ([email],[name]) ON target.[email] = excluded.[email]" +
+				" WHEN MATCHED THEN UPDATE SET [name] = excluded.[name] WHEN NOT MATCHED THEN INSERT ([email],[name]) VALUES (excluded.[email],excluded.[name]) OUTPUT Inserted.[id];",
+		},
+		{
+			onConflict: gorm.OnConflict{Columns: []string{"[email]"}, DoNothing: true},
+			sql: "MERGE INTO [users] WITH (HOLDLOCK) AS target USING (VALUES ($$$,$$$),($$$,$$$)) AS excluded ([email],[name]) ON target.[email] = excluded.[email]" +
+				" WHEN NOT MATCHED THEN INSERT ([email],[name]) VALUES (excluded.[email],excluded.[name]);",
+		},
+		{
+			onConflict: gorm.OnConflict{DoNothing: true},
+			output:     "OUTPUT Inserted.[id]",
+		},
+		{
+			onConflict: gorm.OnConflict{Columns: []string{"[id]"}, DoUpdates: []string{"[name]"}},
+		},
+	}
+
+	for _, test := range tests {
+		sql, err := (mssql{}).UpsertSQL("[users]", columns, rows, test.output, test.onConflict)
+		if test.sql == "" {
+			if err == nil {
+				t.Errorf("expects an error without inserted conflict target %v, but got %v", test.onConflict.Columns, sql)
+			}
+		} else if err != nil || sql != test.sql {
+			t.Errorf("expects upsert SQL %v, but got %v, %v", test.sql, sql, err)
+		}
+	}
+}
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		err     error
+		expects error
+	}{
+		{mssqldb.Error{Number: 2627, Message: "Violation of UNIQUE KEY constraint"}, gorm.ErrDuplicatedKey},
+		{mssqldb.Error{Number: 2601, Message: "Cannot insert duplicate key row"}, gorm.ErrDuplicatedKey},
+		{mssqldb.Error{Number: 547, Message: "The INSERT statement conflicted with the FOREIGN KEY constraint"}, gorm.ErrForeignKeyViolated},
+		{mssqldb.Error{Number: 547, Message: "The INSERT statement conflicted with the CHECK constraint"}, gorm.ErrCheckConstraintViolated},
+		{mssqldb.Error{Number: 515, Message: "Cannot insert the value NULL into column"}, gorm.ErrNotNullViolated},
+		{mssqldb.Error{Number: 208, Message: "Invalid object name"}, nil},
+	}
+
+	for _, test := range tests {
+		if err := (mssql{}).TranslateError(test.err); err != test.expects {
+			t.Errorf("%v should be translated to %v, but got %v", test.err, test.expects, err)
+		}
+	}
+}
+
+func TestLockingSQL(t *testing.T) {
+	tests := []struct {
+		locking   gorm.Locking
+		tableHint string
+	}{
+		{gorm.Locking{Strength: "UPDATE"}, "WITH (UPDLOCK, ROWLOCK)"},
+		{gorm.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}, "WITH (UPDLOCK, ROWLOCK, READPAST)"},
+		{gorm.Locking{Strength: "SHARE", Options: "NOWAIT"}, "WITH (HOLDLOCK, ROWLOCK, NOWAIT)"},
+	}
+
+	for _, test := range tests {
+		if clause, tableHint := (mssql{}).LockingSQL(test.locking); clause != "" || tableHint != test.tableHint {
+			t.Errorf("expects table hint %q, but got %q, %q", test.tableHint, tableHint, clause)
+		}
+	}
+}
+
+func TestLastInsertIDOutputInterstitial(t *testing.T) {
+	dialect, ok := gorm.Dialect(&mssql{}).(gorm.LastInsertIDOutputDialect)
+	if !ok {
+		t.Fatalf("mssql should return inserted IDs with OUTPUT")
+	}
+
+	if output := dialect.LastInsertIDOutputInterstitial("[users]", "[id]", []string{"[name]"}); output != "OUTPUT Inserted.[id]" {
+		t.Errorf("expects OUTPUT clause of the primary key, but got %q", output)
+	}
+}
@@ -0,0 +1,86 @@
+package gorm_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+func TestDryRun(t *testing.T) {
+	user := User{Name: "dry_run_user", Age: 18}
+	tx := DB.DryRun().Create(&user)
+	if tx.Error != nil {
+		t.Errorf("No error should happen when creating in dry run mode, but got %v", tx.Error)
+	}
+
+	if !strings.HasPrefix(tx.SQL, "INSERT INTO "+DB.Dialect().Quote("users")) {
+		t.Errorf("Insert statement should be built in dry run mode, but got %v", tx.SQL)
+	}
+
+	if len(tx.SQLVars) == 0 || user.CreatedAt.IsZero() {
+		t.Errorf("Bind variables should be built and callbacks should be called in dry run mode")
+	}
+
+	if !DB.First(&User{}, "name = ?", "dry_run_user").RecordNotFound() {
+		t.Errorf("Record should not be created in dry run mode")
+	}
+
+	DB.Save(&user)
+	DB.DryRun().Model(&user).Update("name", "dry_run_user_new")
+	DB.DryRun().Delete(&user)
+	if DB.First(&User{}, "name = ?", "dry_run_user").RecordNotFound() {
+		t.Errorf("Record should not be updated or deleted in dry run mode")
+	}
+
+	var users []User
+	if tx := DB.DryRun().Where("name = ?", "dry_run_user").Find(&users); tx.Error != nil || len(users) != 0 || !strings.HasPrefix(tx.SQL, "SELECT") {
+		t.Errorf("Query should be built without executing in dry run mode, but got %v, %v", tx.SQL, tx.Error)
+	}
+
+	var count int
+	if tx := DB.DryRun().Model(&User{}).Where("name = ?", "dry_run_user").Count(&count); tx.Error != nil || count != 0 || !strings.Contains(tx.SQL, "count(*)") {
+		t.Errorf("Count should be built without executing in dry run mode, but got %v, %v", tx.SQL, tx.Error)
+	}
+}
+
+func TestDryRunRows(t *testing.T) {
+	rows, err := DB.DryRun().Model(&User{}).Where("name = ?", "dry_run_user").Rows()
+	if rows != nil || err != gorm.ErrDryRunModeUnsupported {
+		t.Errorf("Rows should return ErrDryRunModeUnsupported in dry run mode, but got %v, %v", rows, err)
+	}
+
+	var name string
+	row := DB.DryRun().Model(&User{}).Select("name").Where("name = ?", "dry_run_user").Row()
+	if row == nil {
+		t.Fatalf("Row should not be nil in dry run mode")
+	}
+
+	if err := row.Scan(&name); err != gorm.ErrDryRunModeUnsupported {
+		t.Errorf("Scanning the row should return ErrDryRunModeUnsupported in dry run mode, but got %v", err)
+	}
+
+	if iter, err := DB.DryRun().Model(&User{}).Where("name = ?", "dry_run_user").Iter(); iter != nil || err != gorm.ErrDryRunModeUnsupported {
+		t.Errorf("Iter should return ErrDryRunModeUnsupported in dry run mode, but got %v, %v", iter, err)
+	}
+}
+
+func TestToSQL(t *testing.T) {
+	sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Where("name = ? AND age > ?", "to_sql_user", 18).Limit(10).Find(&[]User{})
+	})
+
+	if !strings.HasPrefix(sql, "SELECT * FROM "+DB.Dialect().Quote("users")) || !strings.Contains(sql, "name = 'to_sql_user' AND age > '18'") {
+		t.Errorf("ToSQL should return the query with interpolated values, but got %v", sql)
+	}
+
+	birthday := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	sql = DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Where("id = ?", 1).UpdateColumn("birthday", birthday)
+	})
+
+	if !strings.HasPrefix(sql, "UPDATE "+DB.Dialect().Quote("users")) || !strings.Contains(sql, "'2000-01-01 00:00:00'") {
+		t.Errorf("ToSQL should return the update statement with interpolated values, but got %v", sql)
+	}
+}
@@ -16,6 +16,28 @@ var (
 	ErrCantStartTransaction = errors.New("can't start transaction")
 	// ErrUnaddressable unaddressable value
 	ErrUnaddressable = errors.New("using unaddressable value")
+	// ErrStaleObject happens when updating a record whose version has been changed, refer `Version`
+	ErrStaleObject = errors.New("stale object")
+	// ErrInvalidCursor invalid pagination cursor, happens when the cursor passed to `Paginate` is malformed or doesn't match its order columns
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrDuplicatedKey unique constraint violated, translated from driver errors by dialects implementing `ErrorTranslatorDialect`
+	ErrDuplicatedKey = errors.New("duplicated key not allowed")
+	// ErrForeignKeyViolated foreign key constraint violated, translated from driver errors by dialects implementing `ErrorTranslatorDialect`
+	ErrForeignKeyViolated = errors.New("violates foreign key constraint")
+	// ErrCheckConstraintViolated check constraint violated, translated from driver errors by dialects implementing `ErrorTranslatorDialect`
+	ErrCheckConstraintViolated = errors.New("violates check constraint")
+	// ErrNotNullViolated not null constraint violated, translated from driver errors by dialects implementing `ErrorTranslatorDialect`
+	ErrNotNullViolated = errors.New("violates not null constraint")
+	// ErrDestructiveChange happens when applying a schema plan with destructive changes without `gorm:allow_destructive_migration`, refer `Migrator.Apply`
+	ErrDestructiveChange = errors.New("destructive schema change not allowed")
+	// ErrNotSupported happens when the dialect doesn't implement the optional interface of the operation, e.g. `IntrospectionDialect` for `Migrator.Plan`
+	ErrNotSupported = errors.New("not supported by the dialect")
+	// ErrPartialIndexUnsupported happens when creating indexes with `where` on dialects without partial indexes like mysql
+	ErrPartialIndexUnsupported = errors.New("partial index not supported by the dialect")
+	// ErrMissingWhereClause happens when restoring records without primary keys or conditions, which would restore all records, refer `Restore`
+	ErrMissingWhereClause = errors.New("missing WHERE clause")
+	// ErrDryRunModeUnsupported happens when getting rows with `Row` or `Rows` in dry run mode, refer `DryRun`
+	ErrDryRunModeUnsupported = errors.New("not supported in dry run mode")
 )
 
 // Errors contains all happened errors
@@ -23,14 +45,7 @@ type Errors []error
 
 // IsRecordNotFoundError returns current error has record not found error or not
 func IsRecordNotFoundError(err error) bool {
-	if errs, ok := err.(Errors); ok {
-		for _, err := range errs {
-			if err == ErrRecordNotFound {
-				return true
-			}
-		}
-	}
-	return err == ErrRecordNotFound
+	return errors.Is(err, ErrRecordNotFound)
 }
 
 // GetErrors gets all happened errors
@@ -38,6 +53,11 @@ func (errs Errors) GetErrors() []error {
 	return errs
 }
 
+// Unwrap returns all happened errors, so `errors.Is` and `errors.As` could match any of them
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
 // Add adds an error
 func (errs Errors) Add(newErrors ...error) Errors {
 	for _, err := range newErrors {
@@ -70,3 +90,30 @@ func (errs Errors) Error() string {
 	}
 	return strings.Join(errors, "; ")
 }
+
+// translatedError is a driver error translated by dialect, it matches both the translated error and the original one
+type translatedError struct {
+	translated error
+	original   error
+}
+
+func (err *translatedError) Error() string {
+	return err.original.Error()
+}
+
+func (err *translatedError) Unwrap() []error {
+	return []error{err.translated, err.original}
+}
+
+// translateError translate err with dialect if it implements `ErrorTranslatorDialect`
+func translateError(dialect Dialect, err error) error {
+	if translator, ok := dialect.(ErrorTranslatorDialect); ok {
+		var translated *translatedError
+		if _, ok := err.(Errors); !ok && !errors.As(err, &translated) {
+			if translatedErr := translator.TranslateError(err); translatedErr != nil {
+				return &translatedError{translated: translatedErr, original: err}
+			}
+		}
+	}
+	return err
+}
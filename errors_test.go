@@ -18,3 +18,37 @@ func TestErrorsCanBeUsedOutsideGorm(t *testing.T) {
 		t.Fatalf("Gave wrong error, got %s", gErrs.Error())
 	}
 }
+
+func TestErrorsCanBeMatched(t *testing.T) {
+	errs := gorm.Errors{}.Add(errors.New("First"), gorm.ErrRecordNotFound)
+
+	if !errors.Is(errs, gorm.ErrRecordNotFound) || !gorm.IsRecordNotFoundError(errs) {
+		t.Errorf("Any of collected errors should be matched")
+	}
+
+	if errors.Is(errs, gorm.ErrInvalidSQL) {
+		t.Errorf("Errors not collected should not be matched")
+	}
+}
+
+func TestTranslatedErrors(t *testing.T) {
+	DB.DropTableIfExists(&UpsertUser{})
+	DB.AutoMigrate(&UpsertUser{})
+
+	DB.Create(&UpsertUser{Email: "translated_error@example.org"})
+	err := DB.Create(&UpsertUser{Email: "translated_error@example.org"}).Error
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("Unique constraint error should be translated to ErrDuplicatedKey, but got %v", err)
+	}
+
+	if err == nil || err.Error() == gorm.ErrDuplicatedKey.Error() {
+		t.Errorf("Message of the original error should be kept, but got %v", err)
+	}
+
+	db := DB.New()
+	db.AddError(gorm.ErrInvalidSQL)
+	db.AddError(err)
+	if !errors.Is(db.Error, gorm.ErrInvalidSQL) || !errors.Is(db.Error, gorm.ErrDuplicatedKey) {
+		t.Errorf("All collected errors should be matched, but got %v", db.Error)
+	}
+}
@@ -0,0 +1,104 @@
+package gorm_test
+
+import (
+	"testing"
+)
+
+type PermissionUser struct {
+	ID        uint
+	Name      string
+	CreatedBy string `gorm:"<-:create"`
+	UpdatedBy string `gorm:"<-:update"`
+	Score     int    `gorm:"<-:false"`
+	Secret    string `gorm:"->:false"`
+	Remark    string `gorm:"-:migration"`
+}
+
+func TestFieldPermissions(t *testing.T) {
+	DB.DropTableIfExists(&PermissionUser{})
+	if err := DB.AutoMigrate(&PermissionUser{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	if DB.Dialect().HasColumn("permission_users", "remark") {
+		t.Errorf("Fields tagged with `-:migration` should not be migrated")
+	}
+	DB.Exec("ALTER TABLE permission_users ADD remark varchar(255)")
+
+	user := PermissionUser{Name: "permission", CreatedBy: "creator", UpdatedBy: "updater", Score: 10, Secret: "secret", Remark: "remark"}
+	if err := DB.Save(&user).Error; err != nil {
+		t.Fatalf("Failed to create user, got error %v", err)
+	}
+
+	var result struct {
+		CreatedBy string
+		UpdatedBy string
+		Score     int
+		Secret    string
+		Remark    string
+	}
+	DB.Table("permission_users").Where("id = ?", user.ID).Select("created_by, updated_by, score, secret, remark").Scan(&result)
+	if result.CreatedBy != "creator" || result.UpdatedBy != "" || result.Score != 0 || result.Secret != "secret" || result.Remark != "remark" {
+		t.Errorf("Fields should be created with their permissions, but got %#v", result)
+	}
+
+	user.Name = "permission updated"
+	user.CreatedBy = "another creator"
+	user.UpdatedBy = "updater"
+	if err := DB.Save(&user).Error; err != nil {
+		t.Fatalf("Failed to update user, got error %v", err)
+	}
+	DB.Model(&user).Updates(map[string]interface{}{"created_by": "creator from map", "score": 20})
+
+	DB.Table("permission_users").Where("id = ?", user.ID).Select("created_by, updated_by, score, secret, remark").Scan(&result)
+	if result.CreatedBy != "creator" || result.UpdatedBy != "updater" || result.Score != 0 {
+		t.Errorf("Fields should be updated with their permissions, but got %#v", result)
+	}
+
+	DB.Exec("UPDATE permission_users SET score = ? WHERE id = ?", 30, user.ID)
+
+	var user2 PermissionUser
+	if err := DB.First(&user2, user.ID).Error; err != nil {
+		t.Fatalf("Failed to query user, got error %v", err)
+	}
+
+	if user2.Score != 30 {
+		t.Errorf("Read only fields should be scanned, but got %v", user2.Score)
+	}
+
+	if user2.Secret != "" {
+		t.Errorf("Fields tagged with `->:false` should not be scanned, but got %v", user2.Secret)
+	}
+
+	if user2.Remark != "remark" {
+		t.Errorf("Fields tagged with `-:migration` should be mapped, but got %v", user2.Remark)
+	}
+}
+
+type PermissionProfile struct {
+	ID   uint
+	Name string
+}
+
+type PermissionAccount struct {
+	ID        uint
+	Name      string
+	ProfileID uint `gorm:"<-:false"`
+	Profile   PermissionProfile
+}
+
+func TestFieldPermissionsOfBelongsToForeignKey(t *testing.T) {
+	DB.DropTableIfExists(&PermissionAccount{}, &PermissionProfile{})
+	DB.AutoMigrate(&PermissionAccount{}, &PermissionProfile{})
+
+	account := PermissionAccount{Name: "permission", Profile: PermissionProfile{Name: "profile"}}
+	if err := DB.Create(&account).Error; err != nil {
+		t.Fatalf("Failed to create account, got error %v", err)
+	}
+
+	var profileID uint
+	DB.Table("permission_accounts").Where("id = ?", account.ID).Select("profile_id").Row().Scan(&profileID)
+	if account.Profile.ID == 0 || profileID != 0 {
+		t.Errorf("Foreign keys tagged with `<-:false` should not be created with the association, but got %v", profileID)
+	}
+}
@@ -0,0 +1,262 @@
+package gorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// foreignKeyConstraint foreign key constraint of a relationship, held by the table with foreign keys
+type foreignKeyConstraint struct {
+	ForeignKey
+	// Table the table with foreign keys, which is the model's table for belongs to, the associated table for has one and has many,
+	// and the join table for many to many
+	Table string
+}
+
+// DisableForeignKeyConstraintWhenMigrating don't create foreign key constraints of relationships when creating and migrating tables
+func (s *DB) DisableForeignKeyConstraintWhenMigrating(disable bool) *DB {
+	s.parent.disableForeignKeyConstraint = disable
+	return s
+}
+
+// foreignKeyConstraints return foreign key constraints of relationships of the model, the `constraint` tag set their actions,
+// or disable them with `constraint:false`
+//     type User struct {
+//       ID        uint
+//       CompanyID *uint
+//       Company   Company `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+//     }
+func (scope *Scope) foreignKeyConstraints() (constraints []foreignKeyConstraint) {
+	for _, field := range scope.GetModelStruct().StructFields {
+		constraints = append(constraints, scope.relationshipConstraints(field)...)
+	}
+	return constraints
+}
+
+// relationshipConstraints return foreign key constraints of the relationship field, relationships referencing columns other than primary keys
+// and polymorphic ones have no constraints
+func (scope *Scope) relationshipConstraints(field *StructField) (constraints []foreignKeyConstraint) {
+	relationship := field.Relationship
+	if relationship == nil || relationship.PolymorphicType != "" || field.ignoreMigration() {
+		return nil
+	}
+
+	if scope.db.parent != nil && scope.db.parent.disableForeignKeyConstraint {
+		return nil
+	}
+
+	onUpdate, onDelete, ok := parseConstraintTag(field.TagSettings["CONSTRAINT"])
+	if !ok {
+		return nil
+	}
+
+	var (
+		tableName = scope.TableName()
+		toScope   = scope.New(reflect.New(field.Struct.Type).Interface())
+		toTable   = toScope.TableName()
+	)
+
+	newConstraint := func(table string, columns []string, referenceTable string, referenceColumns []string) foreignKeyConstraint {
+		dest := fmt.Sprintf("%v(%v)", referenceTable, strings.Join(referenceColumns, ","))
+		return foreignKeyConstraint{
+			ForeignKey: ForeignKey{
+				Name:             scope.relationshipFKName(table, strings.Join(columns, ","), dest),
+				Columns:          columns,
+				ReferenceTable:   referenceTable,
+				ReferenceColumns: referenceColumns,
+				OnUpdate:         onUpdate,
+				OnDelete:         onDelete,
+			},
+			Table: table,
+		}
+	}
+
+	switch relationship.Kind {
+	case "belongs_to":
+		if isPrimaryColumns(toScope, relationship.AssociationForeignDBNames) {
+			constraints = append(constraints, newConstraint(tableName, relationship.ForeignDBNames, toTable, relationship.AssociationForeignDBNames))
+		}
+	case "has_one", "has_many":
+		if isPrimaryColumns(scope, relationship.AssociationForeignDBNames) {
+			constraints = append(constraints, newConstraint(toTable, relationship.ForeignDBNames, tableName, relationship.AssociationForeignDBNames))
+		}
+	case "many_to_many":
+		joinTable := relationship.JoinTableHandler.Table(scope.db)
+		if isPrimaryColumns(scope, relationship.ForeignFieldNames) {
+			constraints = append(constraints, newConstraint(joinTable, relationship.ForeignDBNames, tableName, relationship.ForeignFieldNames))
+		}
+		if isPrimaryColumns(toScope, relationship.AssociationForeignFieldNames) {
+			constraints = append(constraints, newConstraint(joinTable, relationship.AssociationForeignDBNames, toTable, relationship.AssociationForeignFieldNames))
+		}
+	}
+	return constraints
+}
+
+// parseConstraintTag parse actions from the `constraint` tag like `OnUpdate:CASCADE,OnDelete:SET NULL`, ok is false if it is disabled
+func parseConstraintTag(tag string) (onUpdate, onDelete string, ok bool) {
+	if strings.EqualFold(strings.TrimSpace(tag), "false") {
+		return "", "", false
+	}
+
+	for _, setting := range strings.Split(tag, ",") {
+		values := strings.SplitN(setting, ":", 2)
+		if len(values) != 2 {
+			continue
+		}
+
+		switch strings.ToUpper(strings.TrimSpace(values[0])) {
+		case "ONUPDATE":
+			onUpdate = strings.ToUpper(strings.TrimSpace(values[1]))
+		case "ONDELETE":
+			onDelete = strings.ToUpper(strings.TrimSpace(values[1]))
+		}
+	}
+	return onUpdate, onDelete, true
+}
+
+// isPrimaryColumns return true if columns are primary keys of the model
+func isPrimaryColumns(scope *Scope, columns []string) bool {
+	if len(columns) == 0 {
+		return false
+	}
+
+	for _, column := range columns {
+		field, ok := scope.FieldByName(column)
+		if !ok || !field.IsPrimaryKey {
+			return false
+		}
+	}
+	return true
+}
+
+// definition return the constraint's definition used in `CREATE TABLE` and `ALTER TABLE ... ADD` statements
+func (constraint foreignKeyConstraint) definition(scope *Scope) string {
+	var columns, referenceColumns []string
+	for _, column := range constraint.Columns {
+		columns = append(columns, scope.Quote(column))
+	}
+	for _, column := range constraint.ReferenceColumns {
+		referenceColumns = append(referenceColumns, scope.Quote(column))
+	}
+
+	sql := fmt.Sprintf("CONSTRAINT %v FOREIGN KEY (%v) REFERENCES %v (%v)", scope.Quote(constraint.Name), strings.Join(columns, ","), scope.Quote(constraint.ReferenceTable), strings.Join(referenceColumns, ","))
+	if constraint.OnDelete != "" {
+		sql += " ON DELETE " + constraint.OnDelete
+	}
+	if constraint.OnUpdate != "" {
+		sql += " ON UPDATE " + constraint.OnUpdate
+	}
+	return sql
+}
+
+// inlineForeignKeys return definitions of constraints held by the table, created with the table if referenced tables exist
+func (scope *Scope) inlineForeignKeys(table string, constraints []foreignKeyConstraint) (definitions []string) {
+	for _, constraint := range constraints {
+		if constraint.Table == table && (constraint.ReferenceTable == table || scope.tableExists(constraint.ReferenceTable)) {
+			definitions = append(definitions, constraint.definition(scope))
+		}
+	}
+	return definitions
+}
+
+// tableExists return true if the table exists or is planned to be created by `Migrator.Plan`
+func (scope *Scope) tableExists(table string) bool {
+	if plannedTables, ok := scope.Get("gorm:planned_tables"); ok && plannedTables.(map[string]bool)[table] {
+		return true
+	}
+	return scope.Dialect().HasTable(table)
+}
+
+// tableCreated record the table created by `DB.CreateTable` or `DB.AutoMigrate`
+func (scope *Scope) tableCreated(table string) {
+	if createdTables, ok := scope.Get("gorm:created_tables"); ok {
+		createdTables.(map[string]bool)[table] = true
+	}
+}
+
+// addForeignKeyConstraints add missing constraints of relationships to tables created in the same call, e.g. constraints referencing
+// tables created later, existing tables may hold rows violating them, they are left to `Migrator.Plan`
+func (scope *Scope) addForeignKeyConstraints() *Scope {
+	var (
+		dialect          = scope.Dialect()
+		createdTables, _ = scope.Get("gorm:created_tables")
+	)
+
+	for _, constraint := range scope.foreignKeyConstraints() {
+		if created, _ := createdTables.(map[string]bool); !created[constraint.Table] {
+			continue
+		}
+
+		if !dialect.HasTable(constraint.Table) || !dialect.HasTable(constraint.ReferenceTable) || dialect.HasForeignKey(constraint.Table, constraint.Name) {
+			continue
+		}
+
+		if dialect, ok := dialect.(AddConstraintDialect); ok {
+			scope.Err(dialect.AddConstraint(constraint.Table, constraint.definition(scope)))
+		} else {
+			scope.Raw(fmt.Sprintf("ALTER TABLE %v ADD %v", scope.Quote(constraint.Table), constraint.definition(scope))).Exec()
+		}
+	}
+	return scope
+}
+
+// sortModelsByDependencies sort models so tables referenced by foreign keys are created before tables referencing them
+func sortModelsByDependencies(db *DB, models []interface{}) []interface{} {
+	var (
+		tableNames   = make([]string, len(models))
+		modelTables  = map[string]bool{}
+		dependencies = map[string][]string{}
+	)
+
+	for idx, model := range models {
+		tableNames[idx] = db.tableNameOf(model)
+		modelTables[tableNames[idx]] = true
+	}
+
+	for idx, model := range models {
+		if _, ok := model.(string); ok {
+			continue
+		}
+
+		for _, constraint := range db.NewScope(model).foreignKeyConstraints() {
+			holder := constraint.Table
+			if !modelTables[holder] {
+				// join tables are created with the model
+				holder = tableNames[idx]
+			}
+
+			if holder != constraint.ReferenceTable && modelTables[constraint.ReferenceTable] {
+				dependencies[holder] = append(dependencies[holder], constraint.ReferenceTable)
+			}
+		}
+	}
+
+	var (
+		sorted  []interface{}
+		visited = map[string]bool{}
+		visit   func(tableName string)
+	)
+
+	visit = func(tableName string) {
+		if visited[tableName] {
+			return
+		}
+		visited[tableName] = true
+
+		for _, dependency := range dependencies[tableName] {
+			visit(dependency)
+		}
+
+		for idx, model := range models {
+			if tableNames[idx] == tableName {
+				sorted = append(sorted, model)
+			}
+		}
+	}
+
+	for _, tableName := range tableNames {
+		visit(tableName)
+	}
+	return sorted
+}
@@ -1,6 +1,9 @@
 package gorm
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 // SQLCommon is the minimal database connection functionality gorm requires.  Implemented by *sql.DB.
 type SQLCommon interface {
@@ -10,11 +13,62 @@ type SQLCommon interface {
 	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
+// SQLCommonContext is the context aware database connection functionality, implemented by *sql.DB and *sql.Tx.
+// gorm uses it when available, and falls back to SQLCommon for connections that don't implement it
+type SQLCommonContext interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 type sqlDb interface {
 	Begin() (*sql.Tx, error)
 }
 
+type sqlDbContext interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
 type sqlTx interface {
 	Commit() error
 	Rollback() error
 }
+
+func execContext(ctx context.Context, db SQLCommon, query string, args ...interface{}) (sql.Result, error) {
+	if db, ok := db.(SQLCommonContext); ok {
+		return db.ExecContext(ctx, query, args...)
+	}
+	return db.Exec(query, args...)
+}
+
+func queryContext(ctx context.Context, db SQLCommon, query string, args ...interface{}) (*sql.Rows, error) {
+	if db, ok := db.(SQLCommonContext); ok {
+		return db.QueryContext(ctx, query, args...)
+	}
+	return db.Query(query, args...)
+}
+
+func queryRowContext(ctx context.Context, db SQLCommon, query string, args ...interface{}) *sql.Row {
+	if db, ok := db.(SQLCommonContext); ok {
+		return db.QueryRowContext(ctx, query, args...)
+	}
+	return db.QueryRow(query, args...)
+}
+
+func beginTx(ctx context.Context, db SQLCommon, opts *sql.TxOptions) (SQLCommon, error) {
+	if db, ok := db.(*preparedStmtDB); ok {
+		tx, err := beginTx(ctx, db.SQLCommon, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &preparedStmtTx{Tx: tx.(*sql.Tx), db: db.SQLCommon, stmts: db.stmts}, nil
+	}
+	if db, ok := db.(sqlDbContext); ok {
+		return db.BeginTx(ctx, opts)
+	}
+	if db, ok := db.(sqlDb); ok && db != nil {
+		return db.Begin()
+	}
+	return nil, ErrCantStartTransaction
+}
@@ -0,0 +1,76 @@
+package gorm
+
+import (
+	"database/sql"
+)
+
+// Iterator iterate query results one record at a time, only the current row is held in memory
+//     iter, err := db.Model(&User{}).Where("age > ?", 18).Iter()
+//     defer iter.Close()
+//     for iter.Next() {
+//       var user User
+//       iter.Scan(&user)
+//     }
+type Iterator struct {
+	db      *DB
+	rows    *sql.Rows
+	columns []string
+	err     error
+}
+
+// Iter return an iterator of records that match given conditions, returns ErrDryRunModeUnsupported in dry run mode like `Rows`
+func (s *DB) Iter() (*Iterator, error) {
+	rows, err := s.Rows()
+	if err != nil {
+		return nil, err
+	} else if rows == nil {
+		// rows may not be queried by customized row query callbacks
+		return &Iterator{db: s}, nil
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	return &Iterator{db: s, rows: rows, columns: columns}, nil
+}
+
+// Next prepare the next record for Scan, returns false when there are no more records or an error happened
+func (iter *Iterator) Next() bool {
+	if iter.err != nil || iter.rows == nil {
+		return false
+	}
+
+	if !iter.rows.Next() {
+		iter.err = iter.rows.Err()
+		iter.rows.Close()
+		return false
+	}
+	return true
+}
+
+// Scan scan current record into dest
+func (iter *Iterator) Scan(dest interface{}) error {
+	scope := iter.db.NewScope(dest)
+	scope.scan(iter.rows, iter.columns, scope.Fields())
+
+	if scope.db.Error != nil {
+		iter.err = scope.db.Error
+	}
+	return scope.db.Error
+}
+
+// Err return the error happened during the iteration
+func (iter *Iterator) Err() error {
+	return iter.err
+}
+
+// Close close the underlying rows, it is safe to call it multiple times
+func (iter *Iterator) Close() error {
+	if iter.rows == nil {
+		return nil
+	}
+	return iter.rows.Close()
+}
@@ -1,23 +1,54 @@
 package gorm
 
 import (
+	"context"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"unicode"
 )
 
 var (
-	defaultLogger            = Logger{log.New(os.Stdout, "\r\n", 0)}
+	defaultLogger = NewLogger(log.New(os.Stdout, "\r\n", 0), LoggerConfig{
+		SlowThreshold:             200 * time.Millisecond,
+		LogLevel:                  LogError,
+		IgnoreRecordNotFoundError: true,
+		Colorful:                  isTerminal(os.Stdout),
+	})
 	sqlRegexp                = regexp.MustCompile(`\?`)
 	numericPlaceHolderRegexp = regexp.MustCompile(`\$\d+`)
 )
 
+// RedactedColumns values of these columns are replaced with `***` in logs, same as tagging fields with `redact`, values
+// compared with them in conditions like `password = ?` are redacted too
+var RedactedColumns []string
+
+// redactedVar replace redacted values in logged vars
+type redactedVar struct{}
+
+// redactVars return vars with redacted values replaced
+func redactVars(vars []interface{}, redactedVars map[int]bool) []interface{} {
+	if len(redactedVars) == 0 {
+		return vars
+	}
+
+	results := make([]interface{}, len(vars))
+	for idx, value := range vars {
+		if redactedVars[idx] {
+			value = redactedVar{}
+		}
+		results[idx] = value
+	}
+	return results
+}
+
 func isPrintable(s string) bool {
 	for _, r := range s {
 		if !unicode.IsPrint(r) {
@@ -27,80 +58,83 @@ func isPrintable(s string) bool {
 	return true
 }
 
-var LogFormatter = func(values ...interface{}) (messages []interface{}) {
-	if len(values) > 1 {
-		var (
-			sql             string
-			formattedValues []string
-			level           = values[0]
-			currentTime     = "\n\033[33m[" + NowFunc().Format("2006-01-02 15:04:05") + "]\033[0m"
-			source          = fmt.Sprintf("\033[35m(%v)\033[0m", values[1])
-		)
-
-		messages = []interface{}{source, currentTime}
-
-		if level == "sql" {
-			// duration
-			messages = append(messages, fmt.Sprintf(" \033[36;1m[%.2fms]\033[0m ", float64(values[2].(time.Duration).Nanoseconds()/1e4)/100.0))
-			// sql
-
-			for _, value := range values[4].([]interface{}) {
-				indirectValue := reflect.Indirect(reflect.ValueOf(value))
-				if indirectValue.IsValid() {
-					value = indirectValue.Interface()
-					if t, ok := value.(time.Time); ok {
-						formattedValues = append(formattedValues, fmt.Sprintf("'%v'", t.Format("2006-01-02 15:04:05")))
-					} else if b, ok := value.([]byte); ok {
-						if str := string(b); isPrintable(str) {
-							formattedValues = append(formattedValues, fmt.Sprintf("'%v'", str))
-						} else {
-							formattedValues = append(formattedValues, "'<binary>'")
-						}
-					} else if r, ok := value.(driver.Valuer); ok {
-						if value, err := r.Value(); err == nil && value != nil {
-							formattedValues = append(formattedValues, fmt.Sprintf("'%v'", value))
-						} else {
-							formattedValues = append(formattedValues, "NULL")
-						}
-					} else {
-						formattedValues = append(formattedValues, fmt.Sprintf("'%v'", value))
-					}
+// interpolateSQL replace placeholders of sql with formatted values
+func interpolateSQL(sql string, values []interface{}) string {
+	var formattedValues []string
+	for _, value := range values {
+		if _, ok := value.(redactedVar); ok {
+			formattedValues = append(formattedValues, "***")
+			continue
+		}
+
+		indirectValue := reflect.Indirect(reflect.ValueOf(value))
+		if indirectValue.IsValid() {
+			value = indirectValue.Interface()
+			if t, ok := value.(time.Time); ok {
+				formattedValues = append(formattedValues, fmt.Sprintf("'%v'", t.Format("2006-01-02 15:04:05")))
+			} else if b, ok := value.([]byte); ok {
+				if str := string(b); isPrintable(str) {
+					formattedValues = append(formattedValues, fmt.Sprintf("'%v'", str))
 				} else {
-					formattedValues = append(formattedValues, "NULL")
+					formattedValues = append(formattedValues, "'<binary>'")
 				}
-			}
-
-			// differentiate between $n placeholders or else treat like ?
-			if numericPlaceHolderRegexp.MatchString(values[3].(string)) {
-				sql = values[3].(string)
-				for index, value := range formattedValues {
-					placeholder := fmt.Sprintf(`\$%d([^\d]|$)`, index+1)
-					sql = regexp.MustCompile(placeholder).ReplaceAllString(sql, value+"$1")
+			} else if r, ok := value.(driver.Valuer); ok {
+				if value, err := r.Value(); err == nil && value != nil {
+					formattedValues = append(formattedValues, fmt.Sprintf("'%v'", value))
+				} else {
+					formattedValues = append(formattedValues, "NULL")
 				}
 			} else {
-				formattedValuesLength := len(formattedValues)
-				for index, value := range sqlRegexp.Split(values[3].(string), -1) {
-					sql += value
-					if index < formattedValuesLength {
-						sql += formattedValues[index]
-					}
-				}
+				formattedValues = append(formattedValues, fmt.Sprintf("'%v'", value))
 			}
-
-			messages = append(messages, sql)
-			messages = append(messages, fmt.Sprintf(" \n\033[36;31m[%v]\033[0m ", strconv.FormatInt(values[5].(int64), 10)+" rows affected or returned "))
 		} else {
-			messages = append(messages, "\033[31;1m")
-			messages = append(messages, values[2:]...)
-			messages = append(messages, "\033[0m")
+			formattedValues = append(formattedValues, "NULL")
+		}
+	}
+
+	// differentiate between $n placeholders or else treat like ?
+	if numericPlaceHolderRegexp.MatchString(sql) {
+		for index, value := range formattedValues {
+			placeholder := fmt.Sprintf(`\$%d([^\d]|$)`, index+1)
+			sql = regexp.MustCompile(placeholder).ReplaceAllString(sql, value+"$1")
 		}
+		return sql
 	}
 
-	return
+	var (
+		result                string
+		formattedValuesLength = len(formattedValues)
+	)
+	for index, value := range sqlRegexp.Split(sql, -1) {
+		result += value
+		if index < formattedValuesLength {
+			result += formattedValues[index]
+		}
+	}
+	return result
 }
 
-type logger interface {
-	Print(v ...interface{})
+// LogLevel log level of Logger, logs above the level will be ignored
+type LogLevel int
+
+const (
+	// LogSilent print nothing
+	LogSilent LogLevel = iota + 1
+	// LogError print errors
+	LogError
+	// LogWarn print errors and slow queries
+	LogWarn
+	// LogInfo print all statements
+	LogInfo
+)
+
+// Logger logger interface, logs of SQL statements are printed by `Trace`
+type Logger interface {
+	LogMode(level LogLevel) Logger
+	Info(ctx context.Context, msg string, data ...interface{})
+	Warn(ctx context.Context, msg string, data ...interface{})
+	Error(ctx context.Context, msg string, data ...interface{})
+	Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error)
 }
 
 // LogWriter log writer interface
@@ -108,12 +142,306 @@ type LogWriter interface {
 	Println(v ...interface{})
 }
 
-// Logger default logger
-type Logger struct {
+// VarsTracer could be implemented by loggers to trace statements and their bind variables separately, values of redacted
+// columns are replaced with `***`, refer `RedactedColumns`
+type VarsTracer interface {
+	TraceVars(ctx context.Context, begin time.Time, fc func() (sql string, vars []interface{}, rowsAffected int64), err error)
+}
+
+// LogFormat output format of loggers created by `NewLogger`
+type LogFormat int
+
+const (
+	// LogFormatText human readable text, with values interpolated into SQL
+	LogFormatText LogFormat = iota
+	// LogFormatJSON one JSON object per line
+	LogFormatJSON
+	// LogFormatLogfmt one line of `key=value` pairs per log
+	LogFormatLogfmt
+)
+
+// LoggerConfig config of loggers created by `NewLogger`
+type LoggerConfig struct {
+	// SlowThreshold statements slower than it will be logged at LogWarn level, zero disables it
+	SlowThreshold time.Duration
+	// LogLevel only logs at or below it will be printed
+	LogLevel LogLevel
+	// IgnoreRecordNotFoundError don't log ErrRecordNotFound
+	IgnoreRecordNotFoundError bool
+	// Colorful print logs with colors, only used by LogFormatText
+	Colorful bool
+	// Format output format, LogFormatText by default
+	Format LogFormat
+}
+
+// NewLogger create a Logger printing logs with writer, e.g:
+//     db.SetLogger(gorm.NewLogger(log.New(os.Stdout, "\r\n", 0), gorm.LoggerConfig{LogLevel: gorm.LogInfo}))
+func NewLogger(writer LogWriter, config LoggerConfig) Logger {
+	return &logger{LogWriter: writer, LoggerConfig: config}
+}
+
+// logger default implementation of Logger
+type logger struct {
 	LogWriter
+	LoggerConfig
+}
+
+// logEntry fields of a log, SQL related fields are only set for statements
+type logEntry struct {
+	Time       string        `json:"time"`
+	Level      string        `json:"level"`
+	Caller     string        `json:"caller"`
+	Msg        string        `json:"msg,omitempty"`
+	SQL        string        `json:"sql,omitempty"`
+	Vars       []interface{} `json:"vars,omitempty"`
+	DurationMs *float64      `json:"duration_ms,omitempty"`
+	Rows       *int64        `json:"rows,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// LogMode return a copy of the logger with level
+func (l *logger) LogMode(level LogLevel) Logger {
+	newLogger := *l
+	newLogger.LogLevel = level
+	return &newLogger
+}
+
+// Info print info messages
+func (l *logger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= LogInfo {
+		l.print(logEntry{Level: "info", Msg: fmt.Sprintf(msg, data...)})
+	}
+}
+
+// Warn print warn messages
+func (l *logger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= LogWarn {
+		l.print(logEntry{Level: "warn", Msg: fmt.Sprintf(msg, data...)})
+	}
+}
+
+// Error print error messages, ErrRecordNotFound in data is ignored with IgnoreRecordNotFoundError
+func (l *logger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= LogError && !(l.IgnoreRecordNotFoundError && hasRecordNotFoundError(data)) {
+		l.print(logEntry{Level: "error", Msg: fmt.Sprintf(msg, data...)})
+	}
+}
+
+// Trace print SQL statements, failed statements are printed at LogError level, slow ones at LogWarn level
+func (l *logger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.TraceVars(ctx, begin, func() (string, []interface{}, int64) {
+		sql, rows := fc()
+		return sql, nil, rows
+	}, err)
+}
+
+// TraceVars print SQL statements like Trace, values are interpolated into SQL for LogFormatText
+func (l *logger) TraceVars(ctx context.Context, begin time.Time, fc func() (string, []interface{}, int64), err error) {
+	elapsed := NowFunc().Sub(begin)
+	entry := logEntry{}
+
+	switch {
+	case err != nil && l.LogLevel >= LogError && (!l.IgnoreRecordNotFoundError || !IsRecordNotFoundError(err)):
+		entry.Level, entry.Error = "error", err.Error()
+	case l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.LogLevel >= LogWarn:
+		entry.Level, entry.Msg = "warn", fmt.Sprintf("SLOW SQL >= %v", l.SlowThreshold)
+	case l.LogLevel >= LogInfo:
+		entry.Level = "info"
+	default:
+		return
+	}
+
+	sql, vars, rows := fc()
+	durationMs := float64(elapsed.Nanoseconds()/1e4) / 100.0
+	entry.SQL, entry.DurationMs, entry.Rows = sql, &durationMs, &rows
+	if l.Format == LogFormatText {
+		entry.SQL = interpolateSQL(sql, vars)
+	} else {
+		entry.Vars = logVars(vars)
+	}
+	l.print(entry)
+}
+
+func (l *logger) print(entry logEntry) {
+	entry.Time, entry.Caller = NowFunc().Format("2006-01-02 15:04:05"), fileWithLineNum()
+
+	switch l.Format {
+	case LogFormatJSON:
+		if bytes, err := json.Marshal(entry); err == nil {
+			l.Println(string(bytes))
+		} else {
+			l.Println(fmt.Sprintf(`{"level":"error","msg":%q}`, err.Error()))
+		}
+	case LogFormatLogfmt:
+		l.Println(logfmt(entry))
+	default:
+		messages := []interface{}{
+			l.colorize(fmt.Sprintf("(%v)", entry.Caller), "\033[35m") + "\n" + l.colorize("["+entry.Time+"]", "\033[33m"),
+		}
+
+		if entry.DurationMs != nil {
+			messages = append(messages,
+				l.colorize(fmt.Sprintf("[%.2fms]", *entry.DurationMs), "\033[36;1m"),
+				entry.SQL,
+				"\n"+l.colorize(fmt.Sprintf("[%v rows affected or returned]", *entry.Rows), "\033[36;31m"),
+			)
+		}
+
+		if entry.Error != "" {
+			messages = append(messages, l.colorize(entry.Error, "\033[31;1m"))
+		} else if entry.Msg != "" {
+			messages = append(messages, l.colorize(entry.Msg, map[string]string{"warn": "\033[33m", "error": "\033[31;1m"}[entry.Level]))
+		}
+		l.Println(messages...)
+	}
+}
+
+func (l *logger) colorize(str string, color string) string {
+	if l.Colorful && color != "" {
+		return color + str + "\033[0m"
+	}
+	return str
+}
+
+func hasRecordNotFoundError(data []interface{}) bool {
+	for _, value := range data {
+		if err, ok := value.(error); ok && IsRecordNotFoundError(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// Printer logger of previous versions, adapt it to Logger with `NewPrintLogger`
+type Printer interface {
+	Print(v ...interface{})
+}
+
+// NewPrintLogger adapt the logger of previous versions to Logger, it prints errors by default like before, e.g:
+//     db.SetLogger(gorm.NewPrintLogger(log.New(os.Stdout, "\r\n", 0)))
+func NewPrintLogger(printer Printer) Logger {
+	return &printLogger{Printer: printer, level: LogError}
+}
+
+// printLogger adapt loggers of previous versions to Logger, values are printed positionally like before,
+// e.g. `"sql", file, duration, sql, vars, rows` for statements and `"log", file, message` for messages
+type printLogger struct {
+	Printer
+	level LogLevel
+}
+
+// LogMode return a copy of the logger with level
+func (l *printLogger) LogMode(level LogLevel) Logger {
+	return &printLogger{Printer: l.Printer, level: level}
+}
+
+// Info print info messages
+func (l *printLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= LogInfo {
+		l.Print("log", fileWithLineNum(), fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn print warn messages
+func (l *printLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= LogWarn {
+		l.Print("log", fileWithLineNum(), fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error print error messages
+func (l *printLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= LogError && !hasRecordNotFoundError(data) {
+		l.Print("log", fileWithLineNum(), fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace print SQL statements at LogInfo level
+func (l *printLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.TraceVars(ctx, begin, func() (string, []interface{}, int64) {
+		sql, rows := fc()
+		return sql, nil, rows
+	}, err)
+}
+
+// TraceVars print errors of SQL statements, and the statements with their vars at LogInfo level, redacted vars are printed as `***`
+func (l *printLogger) TraceVars(ctx context.Context, begin time.Time, fc func() (string, []interface{}, int64), err error) {
+	if err != nil {
+		l.Error(ctx, "%v", err)
+	}
+
+	if l.level >= LogInfo {
+		sql, vars, rows := fc()
+		for idx, value := range vars {
+			if _, ok := value.(redactedVar); ok {
+				vars[idx] = "***"
+			}
+		}
+		l.Print("sql", fileWithLineNum(), NowFunc().Sub(begin), sql, vars, rows)
+	}
+}
+
+// logfmt format entry as `key=value` pairs, values are quoted if needed, vars are formatted as a JSON array
+func logfmt(entry logEntry) string {
+	pairs := []string{"time=" + logfmtValue(entry.Time), "level=" + entry.Level, "caller=" + logfmtValue(entry.Caller)}
+	if entry.Msg != "" {
+		pairs = append(pairs, "msg="+logfmtValue(entry.Msg))
+	}
+
+	if entry.DurationMs != nil {
+		pairs = append(pairs, "sql="+logfmtValue(entry.SQL))
+		if len(entry.Vars) > 0 {
+			if bytes, err := json.Marshal(entry.Vars); err == nil {
+				pairs = append(pairs, "vars="+logfmtValue(string(bytes)))
+			}
+		}
+		pairs = append(pairs, fmt.Sprintf("duration_ms=%.2f", *entry.DurationMs), fmt.Sprintf("rows=%v", *entry.Rows))
+	}
+
+	if entry.Error != "" {
+		pairs = append(pairs, "error="+logfmtValue(entry.Error))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func logfmtValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"\t\r\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// logVars convert vars to values could be encoded in structured logs
+func logVars(vars []interface{}) []interface{} {
+	values := make([]interface{}, 0, len(vars))
+	for _, value := range vars {
+		if _, ok := value.(redactedVar); ok {
+			values = append(values, "***")
+			continue
+		}
+
+		if indirectValue := reflect.Indirect(reflect.ValueOf(value)); indirectValue.IsValid() {
+			value = indirectValue.Interface()
+		} else {
+			value = nil
+		}
+
+		if b, ok := value.([]byte); ok {
+			if str := string(b); isPrintable(str) {
+				value = str
+			} else {
+				value = "<binary>"
+			}
+		} else if r, ok := value.(driver.Valuer); ok {
+			value, _ = r.Value()
+		}
+		values = append(values, value)
+	}
+	return values
 }
 
-// Print format & print log
-func (logger Logger) Print(values ...interface{}) {
-	logger.Println(LogFormatter(values...)...)
+// isTerminal check if file is a character device, logs are colorless if stdout is redirected
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
@@ -0,0 +1,224 @@
+package gorm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type logRecorder struct {
+	logs []string
+}
+
+func (recorder *logRecorder) Println(v ...interface{}) {
+	recorder.logs = append(recorder.logs, fmt.Sprintln(v...))
+}
+
+func (recorder *logRecorder) String() string {
+	return strings.Join(recorder.logs, "")
+}
+
+func newRecordedDB(config gorm.LoggerConfig) (*gorm.DB, *logRecorder) {
+	recorder := &logRecorder{}
+	db := DB.New()
+	db.SetLogger(gorm.NewLogger(recorder, config))
+	return db, recorder
+}
+
+func TestLoggerLevels(t *testing.T) {
+	db, recorder := newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogInfo})
+	db.Where("name = ?", "logger_user").Find(&[]User{})
+	if logs := recorder.String(); !strings.Contains(logs, "name = 'logger_user'") {
+		t.Errorf("Statements should be logged with interpolated values at info level, but got %v", logs)
+	}
+
+	if strings.Contains(recorder.String(), "\033[") {
+		t.Errorf("Logs should be colorless if not colorful")
+	}
+
+	db, recorder = newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogError})
+	db.Where("name = ?", "logger_user").Find(&[]User{})
+	if len(recorder.logs) != 0 {
+		t.Errorf("Statements should not be logged at error level, but got %v", recorder.String())
+	}
+
+	db.Table("logger_non_existing_table").Find(&[]User{})
+	if logs := recorder.String(); !strings.Contains(logs, "logger_non_existing_table") {
+		t.Errorf("Failed statements should be logged at error level, but got %v", logs)
+	}
+
+	db, recorder = newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogSilent})
+	db.Table("logger_non_existing_table").Find(&[]User{})
+	if len(recorder.logs) != 0 {
+		t.Errorf("Nothing should be logged at silent level, but got %v", recorder.String())
+	}
+
+	db.LogMode(true).Where("name = ?", "logger_user").Find(&[]User{})
+	if len(recorder.logs) != 1 {
+		t.Errorf("Statements should be logged with LogMode(true), but got %v", recorder.String())
+	}
+}
+
+func TestLoggerRecordNotFound(t *testing.T) {
+	db, recorder := newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogError})
+	db.Where("name = ?", "logger_not_found").First(&User{})
+	if len(recorder.logs) != 1 || !strings.Contains(recorder.String(), gorm.ErrRecordNotFound.Error()) {
+		t.Errorf("ErrRecordNotFound should be logged, but got %v", recorder.String())
+	}
+
+	db, recorder = newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogError, IgnoreRecordNotFoundError: true})
+	db.Where("name = ?", "logger_not_found").First(&User{})
+	if len(recorder.logs) != 0 {
+		t.Errorf("ErrRecordNotFound should be ignored, but got %v", recorder.String())
+	}
+}
+
+type printRecorder struct {
+	values [][]interface{}
+}
+
+func (recorder *printRecorder) Print(v ...interface{}) {
+	recorder.values = append(recorder.values, v)
+}
+
+func TestLoggerOfPrint(t *testing.T) {
+	recorder := &printRecorder{}
+	db := DB.New()
+	db.SetLogger(gorm.NewPrintLogger(recorder))
+
+	db.Where("name = ?", "print_logger_user").Find(&[]User{})
+	if len(recorder.values) != 0 {
+		t.Errorf("Statements should not be printed by default, but got %v", recorder.values)
+	}
+
+	db.Table("print_logger_non_existing_table").Find(&[]User{})
+	if len(recorder.values) != 1 || recorder.values[0][0] != "log" || !strings.Contains(fmt.Sprint(recorder.values[0][2]), "print_logger_non_existing_table") {
+		t.Errorf("Errors should be printed like `\"log\", file, message`, but got %v", recorder.values)
+	}
+
+	recorder.values = nil
+	db.LogMode(true).Where("name = ?", "print_logger_user").Find(&[]User{})
+	if len(recorder.values) != 1 || len(recorder.values[0]) != 6 || recorder.values[0][0] != "sql" {
+		t.Fatalf("Statements should be printed like `\"sql\", file, duration, sql, vars, rows`, but got %v", recorder.values)
+	}
+
+	if vars, ok := recorder.values[0][4].([]interface{}); !ok || !reflect.DeepEqual(vars, []interface{}{"print_logger_user"}) {
+		t.Errorf("Vars of statements should be printed, but got %v", recorder.values[0][4])
+	}
+}
+
+func TestLoggerSlowThreshold(t *testing.T) {
+	db, recorder := newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogWarn, SlowThreshold: time.Nanosecond, Colorful: true})
+	db.Where("name = ?", "logger_user").Find(&[]User{})
+	if logs := recorder.String(); !strings.Contains(logs, "SLOW SQL") || !strings.Contains(logs, "\033[") {
+		t.Errorf("Slow statements should be logged at warn level, but got %v", logs)
+	}
+
+	db, recorder = newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogWarn, SlowThreshold: time.Hour})
+	db.Where("name = ?", "logger_user").Find(&[]User{})
+	if len(recorder.logs) != 0 {
+		t.Errorf("Statements faster than the threshold should not be logged at warn level, but got %v", recorder.String())
+	}
+}
+
+type RedactedUser struct {
+	ID       uint
+	Name     string
+	Password string `gorm:"redact"`
+	Token    string
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	DB.DropTableIfExists(&RedactedUser{})
+	DB.AutoMigrate(&RedactedUser{})
+
+	gorm.RedactedColumns = []string{"token"}
+	defer func() { gorm.RedactedColumns = nil }()
+
+	db, recorder := newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogInfo})
+	user := RedactedUser{Name: "redacted_user", Password: "redacted_password", Token: "redacted_token"}
+	db.Create(&user)
+	db.Model(&user).Updates(map[string]interface{}{"password": "redacted_new_password", "token": "redacted_new_token"})
+	db.Where(&RedactedUser{Password: "redacted_new_password"}).Find(&[]RedactedUser{})
+
+	logs := recorder.String()
+	if strings.Contains(logs, "redacted_password") || strings.Contains(logs, "redacted_new_password") || strings.Contains(logs, "redacted_token") || strings.Contains(logs, "redacted_new_token") {
+		t.Errorf("Values of redacted columns should not be logged, but got %v", logs)
+	}
+
+	if strings.Count(logs, "***") != 5 || !strings.Contains(logs, "redacted_user") {
+		t.Errorf("Values of redacted columns should be replaced with ***, but got %v", logs)
+	}
+}
+
+func TestLoggerRedactionOfConditions(t *testing.T) {
+	DB.DropTableIfExists(&RedactedUser{})
+	DB.AutoMigrate(&RedactedUser{})
+
+	gorm.RedactedColumns = []string{"token"}
+	defer func() { gorm.RedactedColumns = nil }()
+
+	db, recorder := newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogInfo})
+	var count int
+	db.Where("password = ? AND name = ?", "where_password", "redacted_user").Find(&[]RedactedUser{})
+	db.Table("redacted_users").Where(&RedactedUser{Password: "table_password"}).Count(&count)
+	db.Raw(`SELECT * FROM redacted_users WHERE "token" IN (?)`, []string{"raw_token1", "raw_token2"}).Scan(&[]RedactedUser{})
+	db.Exec("UPDATE redacted_users SET token = ? WHERE name = ?", "exec_token", "redacted_user")
+
+	logs := recorder.String()
+	for _, value := range []string{"where_password", "table_password", "raw_token1", "raw_token2", "exec_token"} {
+		if strings.Contains(logs, value) {
+			t.Errorf("Values compared with redacted columns should not be logged, but got %v", logs)
+		}
+	}
+
+	if strings.Count(logs, "***") != 5 || strings.Count(logs, "'redacted_user'") != 2 {
+		t.Errorf("Values compared with redacted columns should be replaced with ***, but got %v", logs)
+	}
+
+	sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("password = ?", "to_sql_password").Find(&[]RedactedUser{})
+	})
+	if strings.Contains(sql, "to_sql_password") || !strings.Contains(sql, "***") {
+		t.Errorf("Values of redacted columns should be replaced with *** in SQL, but got %v", sql)
+	}
+}
+
+func TestLoggerFormats(t *testing.T) {
+	db, recorder := newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogInfo, Format: gorm.LogFormatJSON})
+	db.Where(&RedactedUser{Name: "json_user", Password: "json_password"}).Find(&[]RedactedUser{})
+	db.Table("logger_non_existing_table").Find(&[]RedactedUser{})
+
+	if len(recorder.logs) != 2 {
+		t.Fatalf("Every statement should be logged in one line, but got %v", recorder.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(recorder.logs[0]), &entry); err != nil {
+		t.Fatalf("Logs should be JSON, but got %v", recorder.logs[0])
+	}
+
+	if !strings.Contains(fmt.Sprint(entry["sql"]), "SELECT") || !reflect.DeepEqual(entry["vars"], []interface{}{"json_user", "***"}) ||
+		entry["duration_ms"] == nil || entry["rows"] != float64(0) || entry["caller"] == nil || entry["level"] != "info" {
+		t.Errorf("JSON logs should have fields of the statement, but got %v", recorder.logs[0])
+	}
+
+	if err := json.Unmarshal([]byte(recorder.logs[1]), &entry); err != nil || entry["level"] != "error" || entry["error"] == nil {
+		t.Errorf("JSON logs of failed statements should have the error, but got %v", recorder.logs[1])
+	}
+
+	db, recorder = newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogInfo, Format: gorm.LogFormatLogfmt})
+	db.Where(&RedactedUser{Name: "logfmt_user", Password: "logfmt_password"}).Find(&[]RedactedUser{})
+
+	logs := recorder.String()
+	for _, pair := range []string{"level=info", "sql=\"SELECT", `vars="[\"logfmt_user\",\"***\"]"`, "duration_ms=", "rows=0", "caller="} {
+		if !strings.Contains(logs, pair) {
+			t.Errorf("logfmt logs should contain %v, but got %v", pair, logs)
+		}
+	}
+}
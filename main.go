@@ -1,6 +1,7 @@
 package gorm
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -15,19 +16,30 @@ type DB struct {
 	Error        error
 	RowsAffected int64
 
+	// SQL and SQLVars of the last statement built by this db, also set in dry run mode
+	SQL          string
+	SQLVars      []interface{}
+	redactedVars map[int]bool
+
 	// single db
 	db                SQLCommon
+	ctx               context.Context
 	blockGlobalUpdate bool
-	logMode           int
-	logger            logger
+	logger            Logger
 	search            *search
 	values            map[string]interface{}
+	// dialect bound to the transaction of the db, nil if the db isn't in a transaction or the dialect doesn't need it
+	txDialect Dialect
 
 	// global db
-	parent        *DB
-	callbacks     *Callback
-	dialect       Dialect
-	singularTable bool
+	parent         *DB
+	callbacks      *Callback
+	dialect        Dialect
+	preparedStmts  *preparedStmts
+	namingStrategy NamingStrategy
+	nowFunc        func() time.Time
+
+	disableForeignKeyConstraint bool
 }
 
 // Open initialize a new db connection, need to import driver first, e.g:
@@ -64,11 +76,13 @@ func Open(dialect string, args ...interface{}) (db *DB, err error) {
 	}
 
 	db = &DB{
-		db:        dbSQL,
-		logger:    defaultLogger,
-		values:    map[string]interface{}{},
-		callbacks: DefaultCallback,
-		dialect:   newDialect(dialect, dbSQL),
+		db:             dbSQL,
+		logger:         defaultLogger,
+		values:         map[string]interface{}{},
+		callbacks:      DefaultCallback,
+		dialect:        newDialect(dialect, dbSQL),
+		preparedStmts:  newPreparedStmts(),
+		namingStrategy: DefaultNamingStrategy{},
 	}
 	db.parent = db
 	if err != nil {
@@ -97,16 +111,27 @@ type closer interface {
 
 // Close close current db connection.  If database connection is not an io.Closer, returns an error.
 func (s *DB) Close() error {
-	if db, ok := s.parent.db.(closer); ok {
+	s.parent.preparedStmts.close()
+	if db, ok := unwrapPreparedStmts(s.parent.db).(closer); ok {
 		return db.Close()
 	}
 	return errors.New("can't close current db")
 }
 
+// Plugin could be registered to a db with `Use`, e.g. `Resolver`
+type Plugin interface {
+	Initialize(db *DB) error
+}
+
+// Use register a plugin to current db
+func (s *DB) Use(plugin Plugin) error {
+	return plugin.Initialize(s)
+}
+
 // DB get `*sql.DB` from current connection
 // If the underlying database connection is not a *sql.DB, returns nil
 func (s *DB) DB() *sql.DB {
-	db, _ := s.db.(*sql.DB)
+	db, _ := unwrapPreparedStmts(s.db).(*sql.DB)
 	return db
 }
 
@@ -115,8 +140,27 @@ func (s *DB) CommonDB() SQLCommon {
 	return s.db
 }
 
-// Dialect get dialect
+// WithContext return a new db that runs its queries with the given context, which could be used to cancel them or set a deadline, e.g:
+//     db.WithContext(ctx).Where("name = ?", "jinzhu").Find(&users)
+func (s *DB) WithContext(ctx context.Context) *DB {
+	clone := s.clone()
+	clone.ctx = ctx
+	return clone
+}
+
+// Context return the context of current db, `context.Background()` if it hasn't been set with `WithContext`
+func (s *DB) Context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+// Dialect get dialect, sqlite's runs statements in the transaction if the db is in a transaction
 func (s *DB) Dialect() Dialect {
+	if s.txDialect != nil {
+		return s.txDialect
+	}
 	return s.parent.dialect
 }
 
@@ -128,21 +172,28 @@ func (s *DB) Callback() *Callback {
 	return s.parent.callbacks
 }
 
-// SetLogger replace default logger
-func (s *DB) SetLogger(log logger) {
+// SetLogger replace default logger with a `Logger`, loggers of `LogWriter` could be created with `NewLogger`,
+// loggers of previous versions with the method `Print(v ...interface{})` could be adapted with `NewPrintLogger`
+func (s *DB) SetLogger(log Logger) {
 	s.logger = log
 }
 
 // LogMode set log mode, `true` for detailed logs, `false` for no log, default, will only print error logs
 func (s *DB) LogMode(enable bool) *DB {
 	if enable {
-		s.logMode = 2
+		s.logger = s.logger.LogMode(LogInfo)
 	} else {
-		s.logMode = 1
+		s.logger = s.logger.LogMode(LogSilent)
 	}
 	return s
 }
 
+// SetLogLevel set level of current logger, refer `LogLevel`
+func (s *DB) SetLogLevel(level LogLevel) *DB {
+	s.logger = s.logger.LogMode(level)
+	return s
+}
+
 // BlockGlobalUpdate if true, generates an error on update/delete without where clause.
 // This is to prevent eventual error with empty objects updates/deletions
 func (s *DB) BlockGlobalUpdate(enable bool) *DB {
@@ -155,10 +206,27 @@ func (s *DB) HasBlockGlobalUpdate() bool {
 	return s.blockGlobalUpdate
 }
 
-// SingularTable use singular table by default
+// SingularTable use singular table by default, it only works with `DefaultNamingStrategy`
 func (s *DB) SingularTable(enable bool) {
-	modelStructsMap = newModelStructsMap()
-	s.parent.singularTable = enable
+	if namingStrategy, ok := s.NamingStrategy().(DefaultNamingStrategy); ok {
+		namingStrategy.SingularTable = enable
+		s.parent.namingStrategy = namingStrategy
+	}
+}
+
+// SetNowFunc set the func returning current time of the db, used to set auto timestamps and soft delete records, `NowFunc` is used by default
+//     db.SetNowFunc(func() time.Time { return time.Now().UTC() })
+func (s *DB) SetNowFunc(nowFunc func() time.Time) *DB {
+	s.parent.nowFunc = nowFunc
+	return s
+}
+
+// now return current time with the db's `NowFunc`
+func (s *DB) now() time.Time {
+	if s.parent != nil && s.parent.nowFunc != nil {
+		return s.parent.nowFunc()
+	}
+	return NowFunc()
 }
 
 // NewScope create a scope for current operation
@@ -230,6 +298,61 @@ func (s *DB) Omit(columns ...string) *DB {
 	return s.clone().search.Omit(columns...).db
 }
 
+// OnConflict resolve conflicts of columns when creating, use it with DoUpdate, DoNothing or UpdateAll, columns default to
+// inserted primary keys, otherwise inserted unique columns
+//     db.OnConflict("email").DoUpdate("name", "age").Create(&users)
+//     db.OnConflict().UpdateAll().Create(&user)
+func (s *DB) OnConflict(columns ...string) *DB {
+	return s.clone().search.OnConflict(columns...).db
+}
+
+// DoUpdate update columns with the inserted values when conflicted, refer `OnConflict`
+func (s *DB) DoUpdate(columns ...string) *DB {
+	return s.clone().search.DoUpdate(columns...).db
+}
+
+// DoNothing skip conflicted records when creating, refer `OnConflict`
+func (s *DB) DoNothing() *DB {
+	return s.clone().search.DoNothing().db
+}
+
+// UpdateAll update all inserted columns except primary keys and conflict columns when conflicted, refer `OnConflict`
+func (s *DB) UpdateAll() *DB {
+	return s.clone().search.UpdateAll().db
+}
+
+// Clauses add clauses to the query, `Locking` and `UsePrimary` are supported now
+//     db.Clauses(gorm.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).Find(&jobs)
+func (s *DB) Clauses(clauses ...interface{}) *DB {
+	return s.clone().search.Clauses(clauses...).db
+}
+
+// ForUpdate lock selected rows for updating, same as `Clauses(Locking{Strength: "UPDATE"})`, it is ignored by sqlite
+func (s *DB) ForUpdate() *DB {
+	return s.Clauses(Locking{Strength: "UPDATE"})
+}
+
+// ForShare lock selected rows in share mode, same as `Clauses(Locking{Strength: "SHARE"})`, it is ignored by sqlite
+func (s *DB) ForShare() *DB {
+	return s.Clauses(Locking{Strength: "SHARE"})
+}
+
+// Paginate paginate records by keyset ordered by given columns (default to primary key) rather than OFFSET,
+// cursors of the next and previous page could be got with `Cursors` after querying
+//     result := db.Where("age > ?", 18).Paginate(cursor, 20, "created_at desc", "id desc").Find(&users)
+//     next, prev := result.Cursors()
+func (s *DB) Paginate(cursor string, limit int, orderColumns ...string) *DB {
+	return s.clone().search.Paginate(cursor, limit, orderColumns...).db
+}
+
+// Cursors return cursors of the next and previous page after querying with `Paginate`, empty if there is no such page
+func (s *DB) Cursors() (next string, prev string) {
+	if cursors, ok := s.Get("gorm:pagination_cursors"); ok {
+		return cursors.([2]string)[0], cursors.([2]string)[1]
+	}
+	return "", ""
+}
+
 // Group specify the group method on the find
 func (s *DB) Group(query string) *DB {
 	return s.clone().search.Group(query).db
@@ -309,19 +432,93 @@ func (s *DB) Find(out interface{}, where ...interface{}) *DB {
 	return s.NewScope(out).inlineCondition(where...).callCallbacks(s.parent.callbacks.queries).db
 }
 
+// FindInBatches find records in batches ordered by primary keys, records are paged with the last primary keys rather than OFFSET,
+// the iteration stops when fc returns an error
+//     db.Where("age > ?", 18).FindInBatches(&users, 100, func(tx *gorm.DB, batch int) error {
+//       return nil
+//     })
+func (s *DB) FindInBatches(dest interface{}, batchSize int, fc func(tx *DB, batch int) error) *DB {
+	var (
+		result        = s.clone()
+		scope         = s.NewScope(dest)
+		primaryFields = scope.PrimaryFields()
+		rowsAffected  int64
+	)
+
+	if batchSize <= 0 {
+		result.AddError(errors.New("batch size should be greater than zero"))
+		return result
+	} else if len(primaryFields) == 0 {
+		result.AddError(errors.New("primary key required to find in batches"))
+		return result
+	}
+
+	// page over all primary keys, records sharing the first one may span batches with composite primary keys
+	var primaryKeys, comparators []string
+	for _, primaryField := range primaryFields {
+		primaryKeys = append(primaryKeys, fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(primaryField.DBName)))
+		comparators = append(comparators, ">")
+	}
+	order := strings.Join(primaryKeys, ", ")
+
+	tx := s.Order(order, true).Limit(batchSize)
+	for batch := 1; ; batch++ {
+		batchResult := tx.Find(dest)
+		rowsAffected += batchResult.RowsAffected
+		if batchResult.Error != nil {
+			result.AddError(batchResult.Error)
+			break
+		} else if batchResult.RowsAffected == 0 {
+			break
+		}
+
+		if err := fc(batchResult, batch); err != nil {
+			result.AddError(err)
+			break
+		}
+
+		results := indirect(reflect.ValueOf(dest))
+		if batchResult.RowsAffected < int64(batchSize) || results.Kind() != reflect.Slice || results.Len() == 0 {
+			break
+		}
+
+		last := results.Index(results.Len() - 1)
+		if last.Kind() != reflect.Ptr {
+			last = last.Addr()
+		}
+		var lastValues []interface{}
+		for _, primaryField := range s.NewScope(last.Interface()).PrimaryFields() {
+			lastValues = append(lastValues, primaryField.Field.Interface())
+		}
+		condition, vars := keysetCondition(scope.Dialect(), primaryKeys, comparators, lastValues)
+		tx = s.Order(order, true).Limit(batchSize).Where(condition, vars...)
+	}
+
+	result.RowsAffected = rowsAffected
+	return result
+}
+
 // Scan scan value to a struct
 func (s *DB) Scan(dest interface{}) *DB {
 	return s.NewScope(s.Value).Set("gorm:query_destination", dest).callCallbacks(s.parent.callbacks.queries).db
 }
 
-// Row return `*sql.Row` with given conditions
+// Row return `*sql.Row` with given conditions, its `Scan` returns ErrDryRunModeUnsupported in dry run mode
 func (s *DB) Row() *sql.Row {
-	return s.NewScope(s.Value).row()
+	scope := s.NewScope(s.Value)
+	if row := scope.row(); row != nil || !scope.dryRun() {
+		return row
+	}
+	return dryRunRow(s.Context())
 }
 
-// Rows return `*sql.Rows` with given conditions
+// Rows return `*sql.Rows` with given conditions, ErrDryRunModeUnsupported is returned in dry run mode
 func (s *DB) Rows() (*sql.Rows, error) {
-	return s.NewScope(s.Value).rows()
+	scope := s.NewScope(s.Value)
+	if rows, err := scope.rows(); rows != nil || err != nil || !scope.dryRun() {
+		return rows, err
+	}
+	return nil, ErrDryRunModeUnsupported
 }
 
 // ScanRows scan `*sql.Rows` to give struct
@@ -418,7 +615,7 @@ func (s *DB) Save(value interface{}) *DB {
 	scope := s.NewScope(value)
 	if !scope.PrimaryKeyZero() {
 		newDB := scope.callCallbacks(s.parent.callbacks.updates).db
-		if newDB.Error == nil && newDB.RowsAffected == 0 {
+		if newDB.Error == nil && newDB.RowsAffected == 0 && !scope.dryRun() {
 			return s.New().FirstOrCreate(value)
 		}
 		return newDB
@@ -471,6 +668,25 @@ func (s *DB) Table(name string) *DB {
 	return clone
 }
 
+// PrepareStmt return a new session that executes SQL with cached prepared statements, also works in transactions
+//     db.PrepareStmt().Create(&user)
+func (s *DB) PrepareStmt() *DB {
+	clone := s.clone()
+	clone.db = s.withPreparedStmts(s.db)
+	return clone
+}
+
+// SetPrepareStmt enable or disable executing SQL with cached prepared statements for current db,
+// call it with the db returned by `Open` to enable it globally
+func (s *DB) SetPrepareStmt(enable bool) *DB {
+	if enable {
+		s.db = s.withPreparedStmts(s.db)
+	} else {
+		s.db = unwrapPreparedStmts(s.db)
+	}
+	return s
+}
+
 // Debug start debug mode
 func (s *DB) Debug() *DB {
 	return s.clone().LogMode(true)
@@ -478,13 +694,18 @@ func (s *DB) Debug() *DB {
 
 // Begin begin a transaction
 func (s *DB) Begin() *DB {
+	return s.BeginTx(s.Context(), nil)
+}
+
+// BeginTx begin a transaction with given context and options
+func (s *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) *DB {
 	c := s.clone()
-	if db, ok := c.db.(sqlDb); ok && db != nil {
-		tx, err := db.Begin()
-		c.db = interface{}(tx).(SQLCommon)
-		c.AddError(err)
+	c.ctx = ctx
+	if tx, err := beginTx(ctx, c.db, opts); err == nil {
+		c.db = tx
+		c.txDialect = transactionDialect(s.Dialect(), tx)
 	} else {
-		c.AddError(ErrCantStartTransaction)
+		c.AddError(err)
 	}
 	return c
 }
@@ -509,6 +730,73 @@ func (s *DB) Rollback() *DB {
 	return s
 }
 
+// Transaction start a transaction as a block, the transaction will be committed if the block returns nil,
+// and rolled back if it returns an error or panics. If current db is already in a transaction, a savepoint will
+// be used instead, so only changes made in the block will be rolled back
+//     db.Transaction(func(tx *gorm.DB) error {
+//       if err := tx.Create(&user).Error; err != nil {
+//         return err
+//       }
+//       return tx.Create(&order).Error
+//     })
+func (s *DB) Transaction(fc func(tx *DB) error) (err error) {
+	var (
+		tx        = s.clone()
+		savePoint string
+		completed bool
+	)
+
+	if _, ok := tx.db.(sqlTx); ok {
+		savePoint = fmt.Sprintf("sp%p", tx)
+		if err = tx.SavePoint(savePoint).Error; err != nil {
+			return err
+		}
+	} else if tx = s.Begin(); tx.Error != nil {
+		return tx.Error
+	}
+
+	defer func() {
+		// rollback if the block returned an error or panicked
+		if !completed {
+			if savePoint != "" {
+				tx.RollbackTo(savePoint)
+			} else {
+				tx.Rollback()
+			}
+		}
+	}()
+
+	if err = fc(tx); err == nil {
+		completed = true
+		if savePoint == "" {
+			err = tx.Commit().Error
+		} else {
+			err = tx.releaseSavePoint(savePoint)
+		}
+	}
+	return err
+}
+
+// SavePoint create a savepoint with given name in current transaction
+func (s *DB) SavePoint(name string) *DB {
+	if _, ok := s.db.(sqlTx); ok {
+		s.AddError(s.execSavePoint(savePointSQL(s.Dialect(), name)))
+	} else {
+		s.AddError(ErrInvalidTransaction)
+	}
+	return s
+}
+
+// RollbackTo rollback current transaction to the savepoint with given name
+func (s *DB) RollbackTo(name string) *DB {
+	if _, ok := s.db.(sqlTx); ok {
+		s.AddError(s.execSavePoint(rollbackToSavePointSQL(s.Dialect(), name)))
+	} else {
+		s.AddError(ErrInvalidTransaction)
+	}
+	return s
+}
+
 // NewRecord check if value's primary key is blank
 func (s *DB) NewRecord(value interface{}) bool {
 	return s.NewScope(value).PrimaryKeyZero()
@@ -526,10 +814,14 @@ func (s *DB) RecordNotFound() bool {
 
 // CreateTable create table for models
 func (s *DB) CreateTable(models ...interface{}) *DB {
-	db := s.Unscoped()
+	db := s.Unscoped().Set("gorm:created_tables", map[string]bool{})
+	models = sortModelsByDependencies(db, models)
 	for _, model := range models {
 		db = db.NewScope(model).createTable().db
 	}
+	for _, model := range models {
+		db = db.NewScope(model).addForeignKeyConstraints().db
+	}
 	return db
 }
 
@@ -561,26 +853,32 @@ func (s *DB) DropTableIfExists(values ...interface{}) *DB {
 func (s *DB) HasTable(value interface{}) bool {
 	var (
 		scope     = s.NewScope(value)
-		tableName string
+		tableName = s.tableNameOf(value)
 	)
 
-	if name, ok := value.(string); ok {
-		tableName = name
-	} else {
-		tableName = scope.TableName()
-	}
-
 	has := scope.Dialect().HasTable(tableName)
 	s.AddError(scope.db.Error)
 	return has
 }
 
+// tableNameOf return the table name of the value, which could be a model or a table name
+func (s *DB) tableNameOf(value interface{}) string {
+	if name, ok := value.(string); ok {
+		return name
+	}
+	return s.NewScope(value).TableName()
+}
+
 // AutoMigrate run auto migration for given models, will only add missing fields, won't delete/change current data
 func (s *DB) AutoMigrate(values ...interface{}) *DB {
-	db := s.Unscoped()
+	db := s.Unscoped().Set("gorm:created_tables", map[string]bool{})
+	values = sortModelsByDependencies(db, values)
 	for _, value := range values {
 		db = db.NewScope(value).autoMigrate().db
 	}
+	for _, value := range values {
+		db = db.NewScope(value).addForeignKeyConstraints().db
+	}
 	return db
 }
 
@@ -598,6 +896,47 @@ func (s *DB) DropColumn(column string) *DB {
 	return scope.db
 }
 
+// AlterColumn change the column of the field to its definition in the model, the type is derived from the field like creating tables
+//     db.AlterColumn(&User{}, "Name")
+func (s *DB) AlterColumn(value interface{}, field string) *DB {
+	scope := s.NewScope(value)
+	scope.alterColumn(field)
+	return scope.db
+}
+
+// RenameColumn rename a column of the model's table, names could be field names or column names
+//     db.RenameColumn(&User{}, "Name", "FullName")
+func (s *DB) RenameColumn(value interface{}, oldName string, newName string) *DB {
+	scope := s.NewScope(value)
+	scope.renameColumn(oldName, newName)
+	return scope.db
+}
+
+// RenameTable rename a table, the old and new ones could be models or table names
+//     db.RenameTable("users", "accounts")
+//     db.RenameTable(&User{}, &Account{})
+func (s *DB) RenameTable(oldName interface{}, newName interface{}) *DB {
+	scope := s.NewScope(nil)
+	scope.renameTable(s.tableNameOf(oldName), s.tableNameOf(newName))
+	return scope.db
+}
+
+// RenameIndex rename an index of the model's table
+//     db.RenameIndex(&User{}, "idx_name", "idx_user_name")
+func (s *DB) RenameIndex(value interface{}, oldName string, newName string) *DB {
+	scope := s.NewScope(value)
+	scope.renameIndex(oldName, newName)
+	return scope.db
+}
+
+// DropConstraint drop a constraint of the model's table, like foreign keys and checks
+//     db.DropConstraint(&Pet{}, "fk_pets_owner")
+func (s *DB) DropConstraint(value interface{}, constraintName string) *DB {
+	scope := s.NewScope(value)
+	scope.dropConstraint(constraintName)
+	return scope.db
+}
+
 // AddIndex add index for columns with given name
 func (s *DB) AddIndex(indexName string, columns ...string) *DB {
 	scope := s.Unscoped().NewScope(s.Value)
@@ -663,6 +1002,23 @@ func (s *DB) Preload(column string, conditions ...interface{}) *DB {
 	return s.clone().search.Preload(column, conditions...).db
 }
 
+// DryRun return a new db that runs callbacks and builds statements without executing them, the last built statement
+// will be available from the returned db's `SQL` and `SQLVars`, same as `Set("gorm:dry_run", true)`, rows can't be got with
+// `Row` and `Rows`, they return ErrDryRunModeUnsupported
+func (s *DB) DryRun() *DB {
+	return s.Set("gorm:dry_run", true)
+}
+
+// ToSQL return the statement built in fc in dry run mode, with values interpolated the same way as logs,
+// values of redacted columns are replaced with `***`
+//     sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+//       return tx.Where("name = ?", "jinzhu").Find(&users)
+//     })
+func (s *DB) ToSQL(fc func(tx *DB) *DB) string {
+	tx := fc(s.DryRun())
+	return interpolateSQL(tx.SQL, redactVars(tx.SQLVars, tx.redactedVars))
+}
+
 // Set set setting by name, which could be used in callbacks, will clone a new db, and update its setting
 func (s *DB) Set(name string, value interface{}) *DB {
 	return s.clone().InstantSet(name, value)
@@ -701,13 +1057,11 @@ func (s *DB) SetJoinTableHandler(source interface{}, column string, handler Join
 // AddError add error to the db
 func (s *DB) AddError(err error) error {
 	if err != nil {
-		if err != ErrRecordNotFound {
-			if s.logMode == 0 {
-				go s.print(fileWithLineNum(), err)
-			} else {
-				s.log(err)
-			}
+		if s.parent != nil {
+			err = translateError(s.Dialect(), err)
+		}
 
+		if err != ErrRecordNotFound {
 			errors := Errors(s.GetErrors())
 			errors = errors.Add(err)
 			if len(errors) > 1 {
@@ -737,13 +1091,14 @@ func (s *DB) GetErrors() []error {
 func (s *DB) clone() *DB {
 	db := &DB{
 		db:                s.db,
+		ctx:               s.ctx,
 		parent:            s.parent,
 		logger:            s.logger,
-		logMode:           s.logMode,
 		values:            map[string]interface{}{},
 		Value:             s.Value,
 		Error:             s.Error,
 		blockGlobalUpdate: s.blockGlobalUpdate,
+		txDialect:         s.txDialect,
 	}
 
 	for key, value := range s.values {
@@ -760,18 +1115,35 @@ func (s *DB) clone() *DB {
 	return db
 }
 
-func (s *DB) print(v ...interface{}) {
-	s.logger.Print(v...)
+func (s *DB) execSavePoint(sql string) error {
+	begin := NowFunc()
+	// savepoint names are unique, never prepare them
+	_, err := execContext(s.Context(), unwrapPreparedStmts(s.db), sql)
+	s.trace(begin, sql, nil, err)
+	return err
 }
 
-func (s *DB) log(v ...interface{}) {
-	if s != nil && s.logMode == 2 {
-		s.print(append([]interface{}{"log", fileWithLineNum()}, v...)...)
+// releaseSavePoint release the savepoint, so savepoints won't pile up in long transactions
+func (s *DB) releaseSavePoint(name string) error {
+	if sql := releaseSavePointSQL(s.Dialect(), name); sql != "" {
+		return s.execSavePoint(sql)
 	}
+	return nil
 }
 
-func (s *DB) slog(sql string, t time.Time, vars ...interface{}) {
-	if s.logMode == 2 {
-		s.print("sql", fileWithLineNum(), NowFunc().Sub(t), sql, vars, s.RowsAffected)
+func (s *DB) log(v ...interface{}) {
+	s.logger.Info(s.Context(), "%v", strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+// trace log the statement with its values, and the error happened when executing it
+func (s *DB) trace(begin time.Time, sql string, vars []interface{}, err error) {
+	if tracer, ok := s.logger.(VarsTracer); ok {
+		tracer.TraceVars(s.Context(), begin, func() (string, []interface{}, int64) {
+			return sql, vars, s.RowsAffected
+		}, err)
+	} else {
+		s.logger.Trace(s.Context(), begin, func() (string, int64) {
+			return interpolateSQL(sql, vars), s.RowsAffected
+		}, err)
 	}
 }
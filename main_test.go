@@ -3,11 +3,13 @@ package gorm_test
 import (
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,8 +68,7 @@ func OpenTestConnection() (db *gorm.DB, err error) {
 		db, err = gorm.Open("sqlite3", filepath.Join(os.TempDir(), "gorm.db"))
 	}
 
-	// db.SetLogger(Logger{log.New(os.Stdout, "\r\n", 0)})
-	// db.SetLogger(log.New(os.Stdout, "\r\n", 0))
+	// db.SetLogger(gorm.NewLogger(log.New(os.Stdout, "\r\n", 0), gorm.LoggerConfig{LogLevel: gorm.LogInfo}))
 	if debug := os.Getenv("DEBUG"); debug == "true" {
 		db.LogMode(true)
 	} else if debug == "false" {
@@ -379,6 +380,173 @@ func TestTransaction(t *testing.T) {
 	}
 }
 
+func TestTransactionBlock(t *testing.T) {
+	if err := DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Save(&User{Name: "transaction-block"}).Error
+	}); err != nil {
+		t.Errorf("No error should raise, but got %v", err)
+	}
+
+	if err := DB.First(&User{}, "name = ?", "transaction-block").Error; err != nil {
+		t.Errorf("Should be able to find committed record")
+	}
+
+	returnedErr := errors.New("transaction block error")
+	if err := DB.Transaction(func(tx *gorm.DB) error {
+		tx.Save(&User{Name: "transaction-block-error"})
+		return returnedErr
+	}); err != returnedErr {
+		t.Errorf("Should return the block's error, but got %v", err)
+	}
+
+	if !DB.First(&User{}, "name = ?", "transaction-block-error").RecordNotFound() {
+		t.Errorf("Should not find record after block returned error")
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("Panic should be raised again after rollback")
+			}
+		}()
+
+		DB.Transaction(func(tx *gorm.DB) error {
+			tx.Save(&User{Name: "transaction-block-panic"})
+			panic("transaction block panic")
+		})
+	}()
+
+	if !DB.First(&User{}, "name = ?", "transaction-block-panic").RecordNotFound() {
+		t.Errorf("Should not find record after block panicked")
+	}
+}
+
+func TestNestedTransactionBlock(t *testing.T) {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		tx.Save(&User{Name: "nested-transaction-outer"})
+
+		if err := tx.Transaction(func(tx2 *gorm.DB) error {
+			tx2.Save(&User{Name: "nested-transaction-inner-1"})
+			return errors.New("rollback inner transaction")
+		}); err == nil {
+			t.Errorf("Nested transaction should return the block's error")
+		}
+
+		return tx.Transaction(func(tx2 *gorm.DB) error {
+			return tx2.Save(&User{Name: "nested-transaction-inner-2"}).Error
+		})
+	})
+
+	if err != nil {
+		t.Errorf("No error should raise, but got %v", err)
+	}
+
+	if err := DB.First(&User{}, "name = ?", "nested-transaction-outer").Error; err != nil {
+		t.Errorf("Should find record saved in outer transaction")
+	}
+
+	if !DB.First(&User{}, "name = ?", "nested-transaction-inner-1").RecordNotFound() {
+		t.Errorf("Should not find record saved in rolled back nested transaction")
+	}
+
+	if err := DB.First(&User{}, "name = ?", "nested-transaction-inner-2").Error; err != nil {
+		t.Errorf("Should find record saved in committed nested transaction")
+	}
+}
+
+func TestSavePoint(t *testing.T) {
+	tx := DB.Begin()
+	tx.Save(&User{Name: "savepoint-1"})
+
+	if err := tx.SavePoint("save_point_1").Error; err != nil {
+		t.Fatalf("No error should raise when creating savepoint, but got %v", err)
+	}
+
+	tx.Save(&User{Name: "savepoint-2"})
+
+	if err := tx.RollbackTo("save_point_1").Error; err != nil {
+		t.Fatalf("No error should raise when rolling back to savepoint, but got %v", err)
+	}
+
+	tx.Commit()
+
+	if err := DB.First(&User{}, "name = ?", "savepoint-1").Error; err != nil {
+		t.Errorf("Should find record saved before savepoint")
+	}
+
+	if !DB.First(&User{}, "name = ?", "savepoint-2").RecordNotFound() {
+		t.Errorf("Should not find record saved after savepoint")
+	}
+
+	if err := DB.New().SavePoint("save_point_2").Error; err != gorm.ErrInvalidTransaction {
+		t.Errorf("Should not create savepoint without transaction, but got %v", err)
+	}
+}
+
+type SavePointUser struct {
+	ID   uint
+	Name string
+}
+
+func (user *SavePointUser) AfterCreate() error {
+	if user.Name == "savepoint-callback-failed" {
+		return errors.New("after create error")
+	}
+	return nil
+}
+
+func TestCallbacksInTransactionUseSavePoint(t *testing.T) {
+	DB.DropTableIfExists(&SavePointUser{})
+	DB.AutoMigrate(&SavePointUser{})
+
+	tx := DB.Begin()
+	if err := tx.Create(&SavePointUser{Name: "savepoint-callback"}).Error; err != nil {
+		t.Errorf("No error should raise, but got %v", err)
+	}
+
+	if err := tx.Create(&SavePointUser{Name: "savepoint-callback-failed"}).Error; err == nil {
+		t.Errorf("AfterCreate should return error")
+	}
+
+	var count int
+	tx.Model(&SavePointUser{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Failed create should be rolled back to its savepoint, but found %v records", count)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		t.Errorf("No error should raise when committing, but got %v", err)
+	}
+
+	if err := DB.First(&SavePointUser{}, "name = ?", "savepoint-callback").Error; err != nil {
+		t.Errorf("Should find record created before failed create")
+	}
+
+	if !DB.First(&SavePointUser{}, "name = ?", "savepoint-callback-failed").RecordNotFound() {
+		t.Errorf("Should not find record of failed create")
+	}
+}
+
+func TestSavePointsReleased(t *testing.T) {
+	db, recorder := newRecordedDB(gorm.LoggerConfig{LogLevel: gorm.LogInfo})
+
+	tx := db.Begin()
+	tx.Create(&SavePointUser{Name: "savepoint-released"})
+	tx.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&SavePointUser{Name: "savepoint-released-in-block"}).Error
+	})
+	tx.Commit()
+
+	var (
+		logs     = recorder.String()
+		released = strings.Count(logs, "RELEASE SAVEPOINT sp")
+		created  = strings.Count(logs, "SAVEPOINT sp") - released
+	)
+	if created != 3 || released != created {
+		t.Errorf("Savepoints should be released on success, but created %v and released %v", created, released)
+	}
+}
+
 func TestRow(t *testing.T) {
 	user1 := User{Name: "RowUser1", Age: 1, Birthday: parseTime("2000-1-1")}
 	user2 := User{Name: "RowUser2", Age: 10, Birthday: parseTime("2010-1-1")}
@@ -0,0 +1,289 @@
+// Package migrate run versioned migrations with gorm, applied migrations are recorded in the `schema_migrations` table
+//     runner := migrate.New(db, nil, []*migrate.Migration{{
+//       ID: "201901010000",
+//       Migrate: func(tx *gorm.DB) error {
+//         return tx.AutoMigrate(&User{}).Error
+//       },
+//       Rollback: func(tx *gorm.DB) error {
+//         return tx.DropTable("users").Error
+//       },
+//     }})
+//     err := runner.Migrate()
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+var (
+	// ErrMissingID returned when a migration's ID is blank
+	ErrMissingID = errors.New("migration ID is missing")
+	// ErrDuplicatedID returned when two migrations have the same ID
+	ErrDuplicatedID = errors.New("migration ID is duplicated")
+	// ErrMigrationNotFound returned when migrating or rolling back to an unknown migration
+	ErrMigrationNotFound = errors.New("migration not found")
+	// ErrNoAppliedMigration returned when rolling back without applied migrations
+	ErrNoAppliedMigration = errors.New("no applied migration")
+	// ErrRollbackImpossible returned when rolling back a migration without `Rollback`
+	ErrRollbackImpossible = errors.New("migration can't be rolled back")
+	// ErrLockTimeout returned when the lock is held by others longer than `Options.LockTimeout`
+	ErrLockTimeout = errors.New("timeout waiting for migration lock")
+)
+
+// Migration a versioned migration, migrations are applied in the order they are registered
+type Migration struct {
+	ID       string
+	Migrate  func(tx *gorm.DB) error
+	Rollback func(tx *gorm.DB) error
+}
+
+// Options options of the runner
+type Options struct {
+	// TableName table recording applied migrations, `schema_migrations` by default
+	TableName string
+	// LockTableName table holding the lock row, `schema_migrations_lock` by default
+	LockTableName string
+	// LockTimeout how long to wait for the lock held by others, 1 minute by default
+	LockTimeout time.Duration
+	// LockExpiration locks held longer than it are taken as left by crashed runners and taken over, never expire if zero
+	LockExpiration time.Duration
+}
+
+// DefaultOptions options used if nil is passed to `New`
+var DefaultOptions = &Options{
+	TableName:     "schema_migrations",
+	LockTableName: "schema_migrations_lock",
+	LockTimeout:   time.Minute,
+}
+
+// lockRetryInterval interval to retry acquiring the lock
+var lockRetryInterval = 100 * time.Millisecond
+
+// columns are named explicitly as they are used in conditions, which shouldn't be changed by naming strategies
+type schemaMigration struct {
+	ID        string    `gorm:"column:id;primary_key;size:255"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+type migrationLock struct {
+	ID       int       `gorm:"column:id;primary_key;auto_increment:false"`
+	LockedAt time.Time `gorm:"column:locked_at"`
+}
+
+// Runner run migrations against the db
+type Runner struct {
+	db         *gorm.DB
+	options    Options
+	migrations []*Migration
+}
+
+// New create a runner of migrations
+func New(db *gorm.DB, options *Options, migrations []*Migration) *Runner {
+	if options == nil {
+		options = DefaultOptions
+	}
+
+	runner := &Runner{db: db, options: *options, migrations: migrations}
+	if runner.options.TableName == "" {
+		runner.options.TableName = DefaultOptions.TableName
+	}
+	if runner.options.LockTableName == "" {
+		runner.options.LockTableName = DefaultOptions.LockTableName
+	}
+	if runner.options.LockTimeout <= 0 {
+		runner.options.LockTimeout = DefaultOptions.LockTimeout
+	}
+	return runner
+}
+
+// Migrate apply all pending migrations
+func (r *Runner) Migrate() error {
+	if len(r.migrations) == 0 {
+		return nil
+	}
+	return r.MigrateTo(r.migrations[len(r.migrations)-1].ID)
+}
+
+// MigrateTo apply pending migrations until the migration with the ID
+func (r *Runner) MigrateTo(id string) error {
+	index, err := r.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	return r.withLock(func(applied map[string]bool) error {
+		for _, migration := range r.migrations[:index+1] {
+			if !applied[migration.ID] {
+				if err := r.runMigration(migration); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// RollbackLast rollback the last applied migration
+func (r *Runner) RollbackLast() error {
+	return r.withLock(func(applied map[string]bool) error {
+		for i := len(r.migrations) - 1; i >= 0; i-- {
+			if applied[r.migrations[i].ID] {
+				return r.rollbackMigration(r.migrations[i])
+			}
+		}
+		return ErrNoAppliedMigration
+	})
+}
+
+// RollbackTo rollback applied migrations after the migration with the ID, the migration itself is kept
+func (r *Runner) RollbackTo(id string) error {
+	index, err := r.indexOf(id)
+	if err != nil {
+		return err
+	}
+
+	return r.withLock(func(applied map[string]bool) error {
+		for i := len(r.migrations) - 1; i > index; i-- {
+			if applied[r.migrations[i].ID] {
+				if err := r.rollbackMigration(r.migrations[i]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Unlock release the lock left by a crashed runner
+func (r *Runner) Unlock() error {
+	return r.db.Table(r.options.LockTableName).Where("id = ?", 1).Delete(&migrationLock{}).Error
+}
+
+func (r *Runner) indexOf(id string) (int, error) {
+	ids := map[string]bool{}
+	for _, migration := range r.migrations {
+		if migration.ID == "" {
+			return -1, ErrMissingID
+		} else if ids[migration.ID] {
+			return -1, ErrDuplicatedID
+		}
+		ids[migration.ID] = true
+	}
+
+	for index, migration := range r.migrations {
+		if migration.ID == id {
+			return index, nil
+		}
+	}
+	return -1, ErrMigrationNotFound
+}
+
+// withLock hold the lock row and run fc with IDs of applied migrations
+func (r *Runner) withLock(fc func(applied map[string]bool) error) (err error) {
+	if err = r.createTable(r.options.LockTableName, &migrationLock{}); err != nil {
+		return err
+	}
+
+	var (
+		deadline = time.Now().Add(r.options.LockTimeout)
+		// failing to insert the lock row held by others is expected, don't log it
+		lockDB = r.db.New().LogMode(false)
+	)
+
+	var lock migrationLock
+	for {
+		// truncated to seconds, so the time read back from databases of any precision matches when unlocking
+		lock = migrationLock{ID: 1, LockedAt: time.Now().Truncate(time.Second)}
+		if err = lockDB.Table(r.options.LockTableName).Create(&lock).Error; err == nil {
+			break
+		} else if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+
+		if r.options.LockExpiration > 0 {
+			// take over the expired lock, only one runner could update it as the condition won't match after that
+			takeover := lockDB.Table(r.options.LockTableName).Where("id = ? AND locked_at < ?", 1, lock.LockedAt.Add(-r.options.LockExpiration)).Update("locked_at", lock.LockedAt)
+			if err = takeover.Error; err != nil {
+				return err
+			} else if takeover.RowsAffected == 1 {
+				break
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	defer func() {
+		// only release the lock held by this runner, it may have been taken over by others after expiring
+		unlockErr := r.db.Table(r.options.LockTableName).Where("id = ? AND locked_at = ?", 1, lock.LockedAt).Delete(&migrationLock{}).Error
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	if err = r.createTable(r.options.TableName, &schemaMigration{}); err != nil {
+		return err
+	}
+
+	var records []schemaMigration
+	if err = r.db.Table(r.options.TableName).Find(&records).Error; err != nil {
+		return err
+	}
+
+	applied := map[string]bool{}
+	for _, record := range records {
+		applied[record.ID] = true
+	}
+	return fc(applied)
+}
+
+func (r *Runner) createTable(tableName string, value interface{}) error {
+	if r.db.HasTable(tableName) {
+		return nil
+	}
+
+	if err := r.db.Table(tableName).CreateTable(value).Error; err != nil && !r.db.HasTable(tableName) {
+		// the table may be created by other runners at the same time
+		return err
+	}
+	return nil
+}
+
+func (r *Runner) runMigration(migration *Migration) error {
+	return r.transaction(func(tx *gorm.DB) error {
+		if migration.Migrate != nil {
+			if err := migration.Migrate(tx); err != nil {
+				return fmt.Errorf("failed to run migration %v: %w", migration.ID, err)
+			}
+		}
+		return tx.Table(r.options.TableName).Create(&schemaMigration{ID: migration.ID, AppliedAt: time.Now()}).Error
+	})
+}
+
+func (r *Runner) rollbackMigration(migration *Migration) error {
+	if migration.Rollback == nil {
+		return ErrRollbackImpossible
+	}
+
+	return r.transaction(func(tx *gorm.DB) error {
+		if err := migration.Rollback(tx); err != nil {
+			return fmt.Errorf("failed to rollback migration %v: %w", migration.ID, err)
+		}
+		return tx.Table(r.options.TableName).Where("id = ?", migration.ID).Delete(&schemaMigration{}).Error
+	})
+}
+
+// transaction run fc in a transaction if DDL statements of the dialect could be rolled back
+func (r *Runner) transaction(fc func(tx *gorm.DB) error) error {
+	if dialect, ok := r.db.Dialect().(gorm.TransactionalDDLDialect); ok && !dialect.SupportTransactionalDDL() {
+		return fc(r.db)
+	}
+	return r.db.Transaction(fc)
+}
@@ -0,0 +1,264 @@
+package migrate_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/jinzhu/gorm/migrate"
+)
+
+type Pet struct {
+	ID   uint
+	Name string
+}
+
+type Toy struct {
+	ID    uint
+	PetID uint
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	path := filepath.Join(os.TempDir(), "gorm_migrate.db")
+	os.Remove(path)
+
+	db, err := gorm.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open db, got error %v", err)
+	}
+	return db
+}
+
+func testMigrations() []*migrate.Migration {
+	return []*migrate.Migration{{
+		ID: "1_create_pets",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.CreateTable(&Pet{}).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.DropTable(&Pet{}).Error
+		},
+	}, {
+		ID: "2_seed_pets",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Create(&Pet{Name: "kitty"}).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Where("name = ?", "kitty").Delete(&Pet{}).Error
+		},
+	}, {
+		ID: "3_create_toys",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.CreateTable(&Toy{}).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.DropTable(&Toy{}).Error
+		},
+	}}
+}
+
+func appliedIDs(db *gorm.DB) (ids []string) {
+	db.Table("schema_migrations").Order("id").Pluck("id", &ids)
+	return
+}
+
+func TestMigrate(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	runner := migrate.New(db, nil, testMigrations())
+	if err := runner.MigrateTo("2_seed_pets"); err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	if ids := appliedIDs(db); len(ids) != 2 || db.HasTable(&Toy{}) {
+		t.Errorf("Migrations should be applied until 2_seed_pets, but got %v", ids)
+	}
+
+	if err := runner.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	var count int
+	db.Model(&Pet{}).Count(&count)
+	if ids := appliedIDs(db); len(ids) != 3 || !db.HasTable(&Toy{}) || count != 1 {
+		t.Errorf("All migrations should be applied once, but got %v, %v pets", ids, count)
+	}
+
+	if err := runner.RollbackLast(); err != nil {
+		t.Fatalf("Failed to rollback, got error %v", err)
+	}
+
+	if ids := appliedIDs(db); len(ids) != 2 || db.HasTable(&Toy{}) {
+		t.Errorf("Last migration should be rolled back, but got %v", ids)
+	}
+
+	if err := runner.RollbackTo("1_create_pets"); err != nil {
+		t.Fatalf("Failed to rollback, got error %v", err)
+	}
+
+	db.Model(&Pet{}).Count(&count)
+	if ids := appliedIDs(db); len(ids) != 1 || count != 0 {
+		t.Errorf("Migrations after 1_create_pets should be rolled back, but got %v, %v pets", ids, count)
+	}
+
+	if err := runner.MigrateTo("4_unknown"); err != migrate.ErrMigrationNotFound {
+		t.Errorf("Should got ErrMigrationNotFound, but got %v", err)
+	}
+}
+
+func TestMigrateFailedInTransaction(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	errBroken := errors.New("broken migration")
+	migrations := append(testMigrations()[:1], &migrate.Migration{
+		ID: "2_broken",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.CreateTable(&Toy{}).Error; err != nil {
+				return err
+			}
+			return errBroken
+		},
+	})
+
+	runner := migrate.New(db, nil, migrations)
+	if err := runner.Migrate(); !errors.Is(err, errBroken) {
+		t.Errorf("Should got the error of the failed migration, but got %v", err)
+	}
+
+	if ids := appliedIDs(db); len(ids) != 1 || db.HasTable(&Toy{}) {
+		t.Errorf("Failed migration should be rolled back, but got %v", ids)
+	}
+
+	if err := runner.RollbackLast(); err != nil {
+		t.Errorf("Failed to rollback, got error %v", err)
+	}
+
+	if err := runner.RollbackLast(); err != migrate.ErrNoAppliedMigration {
+		t.Errorf("Should got ErrNoAppliedMigration, but got %v", err)
+	}
+}
+
+func TestMigrateLock(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	var (
+		started  = make(chan bool)
+		finished = make(chan error)
+		runner   = migrate.New(db, &migrate.Options{LockTimeout: 200 * time.Millisecond}, []*migrate.Migration{{
+			ID: "1_slow",
+			Migrate: func(tx *gorm.DB) error {
+				started <- true
+				time.Sleep(500 * time.Millisecond)
+				return nil
+			},
+		}})
+	)
+
+	go func() {
+		finished <- runner.Migrate()
+	}()
+	<-started
+
+	if err := runner.Migrate(); err != migrate.ErrLockTimeout {
+		t.Errorf("Should got ErrLockTimeout when others are migrating, but got %v", err)
+	}
+
+	if err := <-finished; err != nil {
+		t.Errorf("Failed to migrate, got error %v", err)
+	}
+
+	if err := runner.Migrate(); err != nil {
+		t.Errorf("Lock should be released after migrating, but got %v", err)
+	}
+}
+
+func TestMigrateLockExpiration(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	var (
+		runner = migrate.New(db, &migrate.Options{LockTimeout: 200 * time.Millisecond, LockExpiration: time.Hour}, testMigrations())
+		locked = time.Now().Add(-30 * time.Minute)
+	)
+
+	db.Exec("CREATE TABLE schema_migrations_lock (id integer primary key, locked_at datetime)")
+	db.Exec("INSERT INTO schema_migrations_lock (id, locked_at) VALUES (?, ?)", 1, locked)
+	if err := runner.Migrate(); err != migrate.ErrLockTimeout {
+		t.Errorf("Should got ErrLockTimeout when the lock isn't expired, but got %v", err)
+	}
+
+	db.Exec("UPDATE schema_migrations_lock SET locked_at = ?", locked.Add(-time.Hour))
+	if err := runner.Migrate(); err != nil {
+		t.Errorf("Expired lock should be taken over, but got %v", err)
+	}
+}
+
+func TestMigrateLockError(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	db.Exec("CREATE TABLE schema_migrations_lock (id integer primary key)")
+
+	var (
+		started = time.Now()
+		runner  = migrate.New(db, &migrate.Options{LockTimeout: time.Minute}, testMigrations())
+	)
+
+	if err := runner.Migrate(); err == nil || err == migrate.ErrLockTimeout {
+		t.Errorf("Should return the error of acquiring the lock, but got %v", err)
+	} else if time.Since(started) > time.Second {
+		t.Errorf("Should not retry when failed to acquire the lock for other reasons")
+	}
+}
+
+func TestMigrateWithNamingStrategy(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	db.SetNamingStrategy(gorm.DefaultNamingStrategy{CamelCase: true})
+
+	runner := migrate.New(db, &migrate.Options{LockTimeout: 200 * time.Millisecond, LockExpiration: time.Hour}, testMigrations())
+	if err := runner.MigrateTo("1_create_pets"); err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	if err := db.Exec("INSERT INTO schema_migrations_lock (id, locked_at) VALUES (?, ?)", 1, time.Now().Add(-2*time.Hour)).Error; err != nil {
+		t.Fatalf("Columns of the lock table should not be changed by the naming strategy, but got %v", err)
+	}
+
+	if err := runner.Migrate(); err != nil {
+		t.Errorf("Expired lock should be taken over with any naming strategy, but got %v", err)
+	}
+
+	if err := runner.RollbackLast(); err != nil {
+		t.Errorf("Failed to rollback, got error %v", err)
+	}
+}
+
+func TestMigrateLockTakenOver(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	runner := migrate.New(db, &migrate.Options{LockExpiration: time.Hour}, []*migrate.Migration{{
+		ID: "1_taken_over",
+		Migrate: func(tx *gorm.DB) error {
+			// the lock expired and was taken over by another runner while migrating
+			return tx.Exec("UPDATE schema_migrations_lock SET locked_at = ?", time.Now().Add(time.Minute)).Error
+		},
+	}})
+
+	if err := runner.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	var count int
+	if db.Table("schema_migrations_lock").Count(&count); count != 1 {
+		t.Errorf("Lock taken over by others should not be released, but got %v locks", count)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
@@ -463,11 +464,95 @@ func TestMultipleIndexes(t *testing.T) {
 	}
 }
 
-func TestModifyColumnType(t *testing.T) {
-	if dialect := os.Getenv("GORM_DIALECT"); dialect != "postgres" && dialect != "mysql" && dialect != "mssql" {
-		t.Skip("Skipping this because only postgres, mysql and mssql support altering a column type")
+type IndexOption struct {
+	ID        uint
+	FirstName string `gorm:"index:idx_index_options_name,priority:2"`
+	LastName  string `gorm:"index:idx_index_options_name,priority:1,sort:desc,length:10,type:btree,comment:search by name"`
+	DeletedAt *time.Time
+}
+
+type PartialIndexOption struct {
+	ID        uint
+	Email     string `gorm:"unique_index:uix_partial_index_options_email,where:deleted_at IS NULL"`
+	DeletedAt *time.Time
+}
+
+func TestIndexOptions(t *testing.T) {
+	DB.DropTableIfExists(&IndexOption{})
+	if err := DB.AutoMigrate(&IndexOption{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	indexes, err := DB.Migrator().GetIndexes(&IndexOption{})
+	if err != nil {
+		t.Fatalf("Failed to get indexes, got error %v", err)
+	}
+
+	var nameIndex *gorm.Index
+	for idx := range indexes {
+		if indexes[idx].Name == "idx_index_options_name" {
+			nameIndex = &indexes[idx]
+		}
+	}
+	if nameIndex == nil || !reflect.DeepEqual(nameIndex.Columns, []string{"last_name", "first_name"}) {
+		t.Errorf("Columns of composite index should be ordered by priority, but got %v", indexes)
+	}
+
+	if plan, err := DB.Migrator().Plan(&IndexOption{}); err != nil || len(plan) != 0 {
+		t.Errorf("Nothing should be planned for indexes with options, but got %v, %v", plan.SQL(), err)
+	}
+
+}
+
+func TestPartialIndex(t *testing.T) {
+	DB.DropTableIfExists(&PartialIndexOption{})
+	if DB.Dialect().GetName() == "mysql" {
+		// mysql doesn't support partial indexes
+		if err := DB.AutoMigrate(&PartialIndexOption{}).Error; !errors.Is(err, gorm.ErrPartialIndexUnsupported) {
+			t.Errorf("Should got ErrPartialIndexUnsupported, but got %v", err)
+		}
+		return
+	}
+
+	if err := DB.AutoMigrate(&PartialIndexOption{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	if plan, err := DB.Migrator().Plan(&PartialIndexOption{}); err != nil || len(plan) != 0 {
+		t.Errorf("Nothing should be planned for partial indexes, but got %v, %v", plan.SQL(), err)
+	}
+
+	user := PartialIndexOption{Email: "jinzhu@example.org"}
+	DB.Save(&user)
+	if err := DB.Save(&PartialIndexOption{Email: "jinzhu@example.org"}).Error; err == nil {
+		t.Errorf("Should get error when creating duplicated email")
+	}
+
+	DB.Delete(&user)
+	if err := DB.Save(&PartialIndexOption{Email: "jinzhu@example.org"}).Error; err != nil {
+		t.Errorf("Email of soft deleted record should be reusable, but got %v", err)
+	}
+}
+
+func TestAddIndexWithConditions(t *testing.T) {
+	if DB.Dialect().GetName() == "mysql" {
+		// mysql doesn't support partial indexes
+		return
 	}
 
+	// values of conditions are interpolated, as statements creating indexes can't have bind vars
+	if err := DB.Model(&Email{}).Where("email <> ? AND user_id > ?", "it's@example.org", 0).AddIndex("idx_email_email_conditional", "email").Error; err != nil {
+		t.Errorf("Got error when tried to create index with conditions: %+v", err)
+	}
+
+	scope := DB.NewScope(&Email{})
+	if !scope.Dialect().HasIndex(scope.TableName(), "idx_email_email_conditional") {
+		t.Errorf("Email should have index idx_email_email_conditional")
+	}
+	DB.Model(&Email{}).RemoveIndex("idx_email_email_conditional")
+}
+
+func TestModifyColumnType(t *testing.T) {
 	type ModifyColumnType struct {
 		gorm.Model
 		Name1 string `gorm:"length:100"`
@@ -483,3 +568,221 @@ func TestModifyColumnType(t *testing.T) {
 		t.Errorf("No error should happen when ModifyColumn, but got %v", err)
 	}
 }
+
+type RenamedPetV1 struct {
+	ID      uint
+	Name    string `gorm:"size:100;index:idx_renamed_pets_name"`
+	OwnerID uint
+}
+
+func (RenamedPetV1) TableName() string {
+	return "renamed_pets"
+}
+
+type RenamedPet struct {
+	ID       uint
+	FullName string `gorm:"size:200;not null;default:'unknown'"`
+	OwnerID  uint
+}
+
+func (RenamedPet) TableName() string {
+	return "renamed_pets"
+}
+
+func TestRenameAndAlterColumn(t *testing.T) {
+	DB.DropTableIfExists("renamed_pets", "pets_to_rename", "renamed_pet_owners")
+	DB.Exec("CREATE TABLE renamed_pet_owners (id integer PRIMARY KEY)")
+	DB.Exec("CREATE TABLE pets_to_rename (id integer PRIMARY KEY, name varchar(100), owner_id integer, CONSTRAINT fk_renamed_pets_owner FOREIGN KEY (owner_id) REFERENCES renamed_pet_owners(id))")
+	DB.Exec("INSERT INTO renamed_pet_owners (id) VALUES (1)")
+	DB.Exec("INSERT INTO pets_to_rename (id, name, owner_id) VALUES (1, 'kitty', 1)")
+
+	if err := DB.RenameTable("pets_to_rename", &RenamedPetV1{}).Error; err != nil {
+		t.Fatalf("Failed to rename table, got error %v", err)
+	}
+
+	if DB.HasTable("pets_to_rename") || !DB.HasTable(&RenamedPetV1{}) {
+		t.Errorf("Table should be renamed")
+	}
+
+	DB.Model(&RenamedPetV1{}).AddIndex("idx_renamed_pets_name", "name")
+	if err := DB.RenameColumn(&RenamedPet{}, "Name", "FullName").Error; err != nil {
+		t.Fatalf("Failed to rename column, got error %v", err)
+	}
+
+	if err := DB.AlterColumn(&RenamedPet{}, "FullName").Error; err != nil {
+		t.Fatalf("Failed to alter column, got error %v", err)
+	}
+
+	columnTypes, _ := DB.Migrator().ColumnTypes(&RenamedPet{})
+	for _, columnType := range columnTypes {
+		if columnType.Name == "name" {
+			t.Errorf("Column name should be renamed")
+		} else if columnType.Name == "full_name" && (columnType.Nullable || columnType.Length != 200 || !columnType.DefaultValue.Valid) {
+			t.Errorf("Column full_name should be altered, but got %#v", columnType)
+		}
+	}
+
+	if err := DB.RenameIndex(&RenamedPet{}, "idx_renamed_pets_name", "idx_renamed_pets_full_name").Error; err != nil {
+		t.Fatalf("Failed to rename index, got error %v", err)
+	}
+
+	if DB.Dialect().HasIndex("renamed_pets", "idx_renamed_pets_name") || !DB.Dialect().HasIndex("renamed_pets", "idx_renamed_pets_full_name") {
+		t.Errorf("Index should be renamed")
+	}
+
+	DB.Model(&RenamedPet{}).AddUniqueIndex("uix_renamed_pets_owner", "owner_id")
+	if err := DB.RenameIndex(&RenamedPet{}, "uix_renamed_pets_owner", "uix_renamed_pets_owner_id").Error; err != nil {
+		t.Fatalf("Failed to rename unique index, got error %v", err)
+	}
+
+	var renamedUnique bool
+	indexes, _ := DB.Migrator().GetIndexes(&RenamedPet{})
+	for _, index := range indexes {
+		renamedUnique = renamedUnique || (index.Name == "uix_renamed_pets_owner_id" && index.Unique)
+	}
+	if !renamedUnique {
+		t.Errorf("Renamed index should be unique, but got %v", indexes)
+	}
+
+	if err := DB.DropConstraint(&RenamedPet{}, "fk_renamed_pets_owner").Error; err != nil {
+		t.Fatalf("Failed to drop constraint, got error %v", err)
+	}
+
+	if foreignKeys, _ := DB.Migrator().GetForeignKeys(&RenamedPet{}); len(foreignKeys) != 0 {
+		t.Errorf("Foreign key should be dropped, but got %v", foreignKeys)
+	}
+
+	var pet RenamedPet
+	if err := DB.First(&pet, 1).Error; err != nil || pet.FullName != "kitty" || pet.OwnerID != 1 {
+		t.Errorf("Rows should be kept, but got %#v, %v", pet, err)
+	}
+
+	if !DB.Dialect().HasIndex("renamed_pets", "idx_renamed_pets_full_name") {
+		t.Errorf("Indexes should be kept after altering the table")
+	}
+	DB.DropTableIfExists("renamed_pets", "renamed_pet_owners")
+}
+
+func TestSQLiteRebuildTable(t *testing.T) {
+	if DB.Dialect().GetName() != "sqlite3" {
+		t.Skip("Only sqlite rebuilds tables")
+	}
+
+	db, err := gorm.Open("sqlite3", "file:"+filepath.Join(os.TempDir(), "gorm_rebuild.db")+"?_foreign_keys=1")
+	if err != nil {
+		t.Fatalf("Failed to open db, got error %v", err)
+	}
+	defer db.Close()
+
+	db.DropTableIfExists("rebuild_pets", "rebuild_owners")
+	db.Exec("CREATE TABLE rebuild_owners (id integer PRIMARY KEY, name varchar(100))")
+	db.Exec("CREATE TABLE rebuild_pets (id integer PRIMARY KEY, owner_id integer REFERENCES rebuild_owners(id) ON DELETE CASCADE)")
+	db.Exec("INSERT INTO rebuild_owners (id) VALUES (1)")
+	db.Exec("INSERT INTO rebuild_pets (id, owner_id) VALUES (1, 1)")
+
+	tx := db.Begin()
+	if err := tx.Table("rebuild_owners").ModifyColumn("name", "varchar(200)").Error; err == nil {
+		t.Errorf("Should get error when rebuilding tables in transactions with foreign keys enabled")
+	}
+	tx.Rollback()
+
+	if err := db.Table("rebuild_owners").ModifyColumn("name", "varchar(200) NOT NULL").Error; err == nil {
+		t.Errorf("Should get error when copying rows violating the new definition")
+	}
+
+	if db.HasTable("rebuild_owners__temp") || !db.HasTable("rebuild_owners") {
+		t.Errorf("Failed rebuild should be rolled back")
+	}
+
+	if err := db.Table("rebuild_owners").ModifyColumn("name", "varchar(200)").Error; err != nil {
+		t.Errorf("Failed to rebuild table, got error %v", err)
+	}
+
+	var count int
+	if db.Table("rebuild_pets").Count(&count); count != 1 {
+		t.Errorf("Rows referencing the rebuilt table should be kept, but got %v", count)
+	}
+	db.DropTableIfExists("rebuild_pets", "rebuild_owners")
+}
+
+type FKCompany struct {
+	ID   uint
+	Name string
+}
+
+type FKLanguage struct {
+	ID   uint
+	Code string
+}
+
+type FKPet struct {
+	ID       uint
+	FKUserID uint
+}
+
+type FKUser struct {
+	ID          uint
+	CompanyID   *uint
+	Company     FKCompany `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	ManagerID   *uint
+	Manager     *FKUser `gorm:"constraint:false"`
+	Pets        []FKPet
+	FKLanguages []FKLanguage `gorm:"many2many:fk_user_languages"`
+}
+
+func TestForeignKeyConstraintsFromRelationships(t *testing.T) {
+	DB.DropTableIfExists("fk_user_languages", &FKPet{}, &FKUser{}, &FKLanguage{}, &FKCompany{})
+
+	if err := DB.AutoMigrate(&FKPet{}, &FKUser{}, &FKLanguage{}, &FKCompany{}).Error; err != nil {
+		t.Fatalf("Failed to migrate, got error %v", err)
+	}
+
+	foreignKeys, _ := DB.Migrator().GetForeignKeys(&FKUser{})
+	if len(foreignKeys) != 1 || foreignKeys[0].ReferenceTable != "fk_companies" || !reflect.DeepEqual(foreignKeys[0].Columns, []string{"company_id"}) ||
+		foreignKeys[0].OnUpdate != "CASCADE" || foreignKeys[0].OnDelete != "SET NULL" {
+		t.Errorf("Belongs to should create foreign key with actions of the constraint tag, but got %#v", foreignKeys)
+	}
+
+	foreignKeys, _ = DB.Migrator().GetForeignKeys(&FKPet{})
+	if len(foreignKeys) != 1 || foreignKeys[0].ReferenceTable != "fk_users" || !reflect.DeepEqual(foreignKeys[0].Columns, []string{"fk_user_id"}) {
+		t.Errorf("Has many should create foreign key in the associated table, but got %#v", foreignKeys)
+	}
+
+	foreignKeys, _ = DB.Migrator().GetForeignKeys("fk_user_languages")
+	if len(foreignKeys) != 2 {
+		t.Errorf("Join table should reference both tables, but got %#v", foreignKeys)
+	}
+
+	if err := DB.AutoMigrate(&FKPet{}, &FKUser{}, &FKLanguage{}, &FKCompany{}).Error; err != nil {
+		t.Errorf("Migrating again should keep foreign keys, but got error %v", err)
+	}
+
+	if foreignKeys, _ = DB.Migrator().GetForeignKeys(&FKPet{}); len(foreignKeys) != 1 {
+		t.Errorf("Foreign keys shouldn't be duplicated, but got %#v", foreignKeys)
+	}
+
+	db, _ := gorm.Open(DB.Dialect().GetName(), DB.DB())
+	db.DisableForeignKeyConstraintWhenMigrating(true)
+	db.DropTableIfExists("fk_user_languages", &FKPet{}, &FKUser{}, &FKLanguage{}, &FKCompany{})
+	if err := db.CreateTable(&FKPet{}, &FKUser{}, &FKLanguage{}, &FKCompany{}).Error; err != nil {
+		t.Fatalf("Failed to create tables, got error %v", err)
+	}
+
+	for _, table := range []interface{}{&FKPet{}, &FKUser{}, "fk_user_languages"} {
+		if foreignKeys, _ := DB.Migrator().GetForeignKeys(table); len(foreignKeys) != 0 {
+			t.Errorf("Foreign keys shouldn't be created if disabled, but got %#v", foreignKeys)
+		}
+	}
+
+	DB.Create(&FKPet{FKUserID: 42})
+	if err := DB.AutoMigrate(&FKPet{}, &FKUser{}, &FKLanguage{}, &FKCompany{}).Error; err != nil {
+		t.Errorf("Migrating existing tables shouldn't fail with orphan rows, but got error %v", err)
+	}
+
+	var count int
+	DB.Model(&FKPet{}).Where("fk_user_id = ?", 42).Count(&count)
+	if foreignKeys, _ := DB.Migrator().GetForeignKeys(&FKPet{}); len(foreignKeys) != 0 || count != 1 {
+		t.Errorf("Foreign keys shouldn't be added to existing tables, but got %#v, %v orphan rows", foreignKeys, count)
+	}
+	DB.DropTableIfExists("fk_user_languages", &FKPet{}, &FKUser{}, &FKLanguage{}, &FKCompany{})
+}
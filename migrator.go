@@ -0,0 +1,444 @@
+package gorm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SchemaChangeKind kind of a schema change
+type SchemaChangeKind string
+
+const (
+	// CreateTableChange create a missing table
+	CreateTableChange SchemaChangeKind = "CreateTable"
+	// AddColumnChange add a missing column
+	AddColumnChange SchemaChangeKind = "AddColumn"
+	// AlterColumnTypeChange change the type or size of a column, it is destructive
+	AlterColumnTypeChange SchemaChangeKind = "AlterColumnType"
+	// AlterColumnNullChange change the nullability of a column
+	AlterColumnNullChange SchemaChangeKind = "AlterColumnNull"
+	// AlterColumnDefaultChange change the default value of a column
+	AlterColumnDefaultChange SchemaChangeKind = "AlterColumnDefault"
+	// DropColumnChange drop a column without field, it is destructive
+	DropColumnChange SchemaChangeKind = "DropColumn"
+	// AddIndexChange add a missing index
+	AddIndexChange SchemaChangeKind = "AddIndex"
+	// DropIndexChange drop a stale or changed index, it is destructive
+	DropIndexChange SchemaChangeKind = "DropIndex"
+	// AddForeignKeyChange add a missing foreign key constraint of a relationship
+	AddForeignKeyChange SchemaChangeKind = "AddForeignKey"
+)
+
+// SchemaChange a change making the database match models, planned by `Migrator.Plan`
+type SchemaChange struct {
+	Kind SchemaChangeKind
+	// Table table of the change
+	Table string
+	// Name name of the changed column or index
+	Name string
+	// Description describe what differs, e.g. `varchar(100) => varchar(200)`
+	Description string
+	// SQL the statement applying the change
+	SQL string
+	// Destructive destructive changes may lose data, they are applied only if allowed
+	Destructive bool
+
+	// apply apply the change instead of executing SQL, for changes depending on the table when applying, like rebuilding sqlite tables
+	apply func(db *DB) error
+}
+
+func (change SchemaChange) String() string {
+	return change.SQL
+}
+
+// SchemaPlan ordered changes of a plan
+type SchemaPlan []SchemaChange
+
+// SQL return statements of the plan
+func (plan SchemaPlan) SQL() (sqls []string) {
+	for _, change := range plan {
+		sqls = append(sqls, change.SQL)
+	}
+	return
+}
+
+// Destructive return true if any change of the plan is destructive
+func (plan SchemaPlan) Destructive() bool {
+	for _, change := range plan {
+		if change.Destructive {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrator plan and apply schema changes, get it with `DB.Migrator`
+type Migrator struct {
+	db *DB
+}
+
+// Migrator return the migrator of current db
+func (s *DB) Migrator() Migrator {
+	return Migrator{db: s}
+}
+
+// Plan compare models to the database and return changes making the database match them, it doesn't change the database
+//     plan, err := db.Migrator().Plan(&User{}, &Product{})
+//     fmt.Println(strings.Join(plan.SQL(), ";\n"))
+func (m Migrator) Plan(models ...interface{}) (plan SchemaPlan, err error) {
+	var (
+		plannedTables = map[string]bool{}
+		db            = m.db.Unscoped().Set("gorm:planned_tables", plannedTables)
+	)
+
+	for _, model := range sortModelsByDependencies(db, models) {
+		changes, err := db.NewScope(model).planSchemaChanges(plannedTables)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, changes...)
+	}
+	return plan, nil
+}
+
+// Apply execute changes of the plan in order, destructive changes are refused unless allowed with `gorm:allow_destructive_migration`
+//     db.Set("gorm:allow_destructive_migration", true).Migrator().Apply(plan)
+func (m Migrator) Apply(plan SchemaPlan) error {
+	if allowed, _ := m.db.Get("gorm:allow_destructive_migration"); allowed != true && plan.Destructive() {
+		return ErrDestructiveChange
+	}
+
+	for _, change := range plan {
+		var err error
+		if change.apply != nil {
+			err = change.apply(m.db)
+		} else {
+			err = m.db.NewScope(nil).Raw(change.SQL).Exec().db.Error
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to apply %v of %v: %w", change.Kind, change.Table, err)
+		}
+	}
+	return nil
+}
+
+// GetTables return tables of current database
+func (m Migrator) GetTables() ([]string, error) {
+	dialect, ok := m.db.Dialect().(IntrospectionDialect)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return dialect.GetTables()
+}
+
+// ColumnTypes return columns of the model's table in database, value could be a model or a table name
+//     columnTypes, err := db.Migrator().ColumnTypes(&User{})
+func (m Migrator) ColumnTypes(value interface{}) ([]ColumnType, error) {
+	dialect, ok := m.db.Dialect().(IntrospectionDialect)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return dialect.ColumnTypes(m.db.tableNameOf(value))
+}
+
+// GetIndexes return indexes of the model's table in database, value could be a model or a table name
+func (m Migrator) GetIndexes(value interface{}) ([]Index, error) {
+	dialect, ok := m.db.Dialect().(IntrospectionDialect)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return dialect.GetIndexes(m.db.tableNameOf(value))
+}
+
+// GetForeignKeys return foreign key constraints of the model's table in database, value could be a model or a table name
+func (m Migrator) GetForeignKeys(value interface{}) ([]ForeignKey, error) {
+	dialect, ok := m.db.Dialect().(IntrospectionDialect)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return dialect.GetForeignKeys(m.db.tableNameOf(value))
+}
+
+// planSchemaChanges diff the model with its table, plannedTables are tables already planned to be created
+func (scope *Scope) planSchemaChanges(plannedTables map[string]bool) (changes SchemaPlan, err error) {
+	var (
+		dialect   = scope.Dialect()
+		tableName = scope.TableName()
+	)
+
+	if plannedTables[tableName] {
+		return scope.planJoinTables(plannedTables), nil
+	}
+
+	if !dialect.HasTable(tableName) {
+		plannedTables[tableName] = true
+		changes = append(changes, SchemaChange{Kind: CreateTableChange, Table: tableName, SQL: scope.createTableSQL()})
+		for _, index := range scope.modelIndexes() {
+			change, err := scope.addIndexChange(index)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, change)
+		}
+		// join tables reference the table, create them after it
+		return append(changes, scope.planJoinTables(plannedTables)...), nil
+	}
+
+	introspection, ok := dialect.(IntrospectionDialect)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+
+	changes = scope.planJoinTables(plannedTables)
+
+	columnTypes, err := introspection.ColumnTypes(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := introspection.GetIndexes(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		addColumns, alterColumns, dropIndexes, addIndexes, dropColumns SchemaPlan
+		existingColumns                                                = map[string]ColumnType{}
+		fieldColumns                                                   = map[string]bool{}
+		uniqueColumns                                                  = map[string]bool{}
+	)
+
+	for _, columnType := range columnTypes {
+		existingColumns[strings.ToLower(columnType.Name)] = columnType
+	}
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		// columns of ignored fields are kept
+		fieldColumns[strings.ToLower(field.DBName)] = true
+		if !field.IsNormal || field.ignoreMigration() {
+			continue
+		}
+
+		if _, ok := field.TagSettings["UNIQUE"]; ok {
+			uniqueColumns[strings.ToLower(field.DBName)] = true
+		}
+
+		columnType, ok := existingColumns[strings.ToLower(field.DBName)]
+		if !ok {
+			addColumns = append(addColumns, SchemaChange{
+				Kind:  AddColumnChange,
+				Table: tableName,
+				Name:  field.DBName,
+				SQL:   fmt.Sprintf("ALTER TABLE %v ADD %v %v", scope.QuotedTableName(), scope.Quote(field.DBName), dialect.DataTypeOf(field)),
+			})
+			continue
+		}
+
+		// changing primary keys isn't supported
+		if field.IsPrimaryKey {
+			continue
+		}
+
+		if dataType := columnDataType(dialect, field); normalizeDataType(dataType) != normalizeDataType(columnType.DatabaseType) {
+			change := scope.alterColumnChange(AlterColumnTypeChange, field)
+			change.Description = fmt.Sprintf("%v => %v", columnType.DatabaseType, dataType)
+			change.Destructive = true
+			alterColumns = append(alterColumns, change)
+		}
+
+		if _, notNull := field.TagSettings["NOT NULL"]; notNull == columnType.Nullable {
+			change := scope.alterColumnChange(AlterColumnNullChange, field)
+			change.Description = fmt.Sprintf("nullable %v => %v", columnType.Nullable, !notNull)
+			alterColumns = append(alterColumns, change)
+		}
+
+		defaultValue, hasDefault := field.TagSettings["DEFAULT"]
+		if hasDefault != columnType.DefaultValue.Valid || (hasDefault && normalizeDefaultValue(defaultValue) != normalizeDefaultValue(columnType.DefaultValue.String)) {
+			change := scope.alterColumnChange(AlterColumnDefaultChange, field)
+			change.Description = fmt.Sprintf("default %v => %v", columnType.DefaultValue.String, defaultValue)
+			alterColumns = append(alterColumns, change)
+		}
+	}
+
+	modelIndexes := map[string]Index{}
+	for _, index := range scope.modelIndexes() {
+		modelIndexes[strings.ToLower(index.Name)] = index
+	}
+
+	existingIndexes := map[string]bool{}
+	for _, index := range indexes {
+		if index.Primary || (index.Unique && len(index.Columns) == 1 && uniqueColumns[strings.ToLower(index.Columns[0])]) {
+			// skip primary keys and indexes of unique columns
+			continue
+		}
+
+		modelIndex, ok := modelIndexes[strings.ToLower(index.Name)]
+		if ok && modelIndex.Unique == index.Unique && strings.EqualFold(strings.Join(modelIndex.Columns, ","), strings.Join(index.Columns, ",")) {
+			existingIndexes[strings.ToLower(index.Name)] = true
+			continue
+		}
+
+		dropIndexes = append(dropIndexes, SchemaChange{
+			Kind:        DropIndexChange,
+			Table:       tableName,
+			Name:        index.Name,
+			Description: fmt.Sprintf("columns %v", strings.Join(index.Columns, ", ")),
+			SQL:         dropIndexSQL(dialect, tableName, index.Name),
+			Destructive: true,
+		})
+	}
+
+	for _, index := range scope.modelIndexes() {
+		if !existingIndexes[strings.ToLower(index.Name)] {
+			change, err := scope.addIndexChange(index)
+			if err != nil {
+				return nil, err
+			}
+			addIndexes = append(addIndexes, change)
+		}
+	}
+
+	for _, columnType := range columnTypes {
+		if !fieldColumns[strings.ToLower(columnType.Name)] {
+			dropColumns = append(dropColumns, SchemaChange{
+				Kind:        DropColumnChange,
+				Table:       tableName,
+				Name:        columnType.Name,
+				SQL:         fmt.Sprintf("ALTER TABLE %v DROP COLUMN %v", scope.QuotedTableName(), scope.Quote(columnType.Name)),
+				Destructive: true,
+			})
+		}
+	}
+
+	changes = append(changes, addColumns...)
+	changes = append(changes, alterColumns...)
+	changes = append(changes, dropIndexes...)
+	changes = append(changes, addIndexes...)
+	changes = append(changes, scope.planForeignKeys(plannedTables)...)
+	return append(changes, dropColumns...), nil
+}
+
+// planJoinTables plan to create missing join tables of the model
+func (scope *Scope) planJoinTables(plannedTables map[string]bool) (changes SchemaPlan) {
+	for _, field := range scope.GetModelStruct().StructFields {
+		if relationship := field.Relationship; relationship != nil && relationship.JoinTableHandler != nil {
+			if joinTable := relationship.JoinTableHandler.Table(scope.db); !plannedTables[joinTable] && !scope.Dialect().HasTable(joinTable) {
+				plannedTables[joinTable] = true
+				changes = append(changes, SchemaChange{Kind: CreateTableChange, Table: joinTable, SQL: scope.createJoinTableSQL(field)})
+			}
+		}
+	}
+	return changes
+}
+
+// planForeignKeys plan to add missing foreign key constraints held by the model's existing table,
+// dialects adding constraints by rebuilding tables are skipped as the rebuilding statements depend on the table when applying
+func (scope *Scope) planForeignKeys(plannedTables map[string]bool) (changes SchemaPlan) {
+	dialect := scope.Dialect()
+	if _, ok := dialect.(AddConstraintDialect); ok {
+		return nil
+	}
+
+	tableName := scope.TableName()
+	for _, constraint := range scope.foreignKeyConstraints() {
+		if constraint.Table != tableName || dialect.HasForeignKey(tableName, constraint.Name) || !scope.tableExists(constraint.ReferenceTable) {
+			continue
+		}
+
+		changes = append(changes, SchemaChange{
+			Kind:        AddForeignKeyChange,
+			Table:       tableName,
+			Name:        constraint.Name,
+			Description: fmt.Sprintf("%v => %v(%v)", strings.Join(constraint.Columns, ", "), constraint.ReferenceTable, strings.Join(constraint.ReferenceColumns, ", ")),
+			SQL:         fmt.Sprintf("ALTER TABLE %v ADD %v", scope.QuotedTableName(), constraint.definition(scope)),
+		})
+	}
+	return changes
+}
+
+// alterColumnChange plan to change the column to the definition of the field, dialects can't alter columns with statements
+// return blank SQL from `AlterColumnSQL`, their columns are altered by `AlterTableDialect.AlterColumn` when applying
+func (scope *Scope) alterColumnChange(kind SchemaChangeKind, field *StructField) SchemaChange {
+	var (
+		tableName = scope.TableName()
+		change    = SchemaChange{Kind: kind, Table: tableName, Name: field.DBName, SQL: alterColumnSQL(scope.Dialect(), kind, tableName, field)}
+	)
+
+	if change.SQL == "" {
+		change.SQL = fmt.Sprintf("-- rebuild %v to alter column %v", scope.QuotedTableName(), scope.Quote(field.DBName))
+		change.apply = func(db *DB) error {
+			dialect, ok := db.Dialect().(AlterTableDialect)
+			if !ok {
+				return ErrNotSupported
+			}
+			return dialect.AlterColumn(tableName, field)
+		}
+	}
+	return change
+}
+
+func (scope *Scope) addIndexChange(index Index) (SchemaChange, error) {
+	sqls, err := scope.addIndexSQL(index)
+	return SchemaChange{
+		Kind:        AddIndexChange,
+		Table:       scope.TableName(),
+		Name:        index.Name,
+		Description: fmt.Sprintf("columns %v", strings.Join(index.Columns, ", ")),
+		SQL:         strings.Join(sqls, ";\n"),
+	}, err
+}
+
+var (
+	// dataTypeAliases aliases of data types returned by databases, longer names go first
+	dataTypeAliases = [][2]string{
+		{"character varying", "varchar"},
+		{"double precision", "double"},
+		{"timestamptz", "timestamp with time zone"},
+		{"bigserial", "bigint"},
+		{"character", "char"},
+		{"decimal", "numeric"},
+		{"serial", "integer"},
+		{"float8", "double"},
+		{"int4", "integer"},
+		{"int8", "bigint"},
+		{"bool", "boolean"},
+		{"int", "integer"},
+	}
+	dataTypeConstraintsRegexp = regexp.MustCompile(`\b(primary key|autoincrement|auto_increment|identity\s*\(\d+,\s*\d+\)|not null|null)\b`)
+	integerDisplayWidthRegexp = regexp.MustCompile(`^(tinyint|smallint|mediumint|integer|bigint)\(\d+\)`)
+)
+
+// normalizeDataType normalize data types to compare types of models with the database, e.g. `int(11)` and `INT` are the same
+func normalizeDataType(dataType string) string {
+	dataType = strings.ToLower(dataType)
+	dataType = dataTypeConstraintsRegexp.ReplaceAllString(dataType, "")
+	dataType = strings.Join(strings.Fields(dataType), " ")
+	if dataType == "tinyint(1)" {
+		return "boolean"
+	}
+
+	for _, alias := range dataTypeAliases {
+		if strings.HasPrefix(dataType, alias[0]) {
+			if rest := dataType[len(alias[0]):]; rest == "" || rest[0] == '(' || rest[0] == ' ' {
+				dataType = alias[1] + rest
+				break
+			}
+		}
+	}
+	return integerDisplayWidthRegexp.ReplaceAllString(dataType, "$1")
+}
+
+// normalizeDefaultValue normalize default values to compare defaults of models with the database, e.g. `'a'` and `('a'::character varying)` are the same
+func normalizeDefaultValue(value string) string {
+	value = strings.TrimSpace(value)
+	for strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+
+	if idx := strings.LastIndex(value, "::"); idx > 0 {
+		value = value[:idx]
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.Trim(value, "'")), "()")
+}
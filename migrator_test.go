@@ -0,0 +1,212 @@
+package gorm_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+type SchemaDiffUserV1 struct {
+	ID       uint
+	Name     string `gorm:"size:100"`
+	Nickname string `gorm:"index:idx_schema_diff_nickname"`
+	Status   string `gorm:"default:'active'"`
+	Legacy   string
+}
+
+func (SchemaDiffUserV1) TableName() string {
+	return "schema_diff_users"
+}
+
+type SchemaDiffUser struct {
+	ID       uint
+	Name     string `gorm:"size:200;not null"`
+	Nickname string
+	Email    string `gorm:"index:idx_schema_diff_email"`
+	Status   string `gorm:"default:'inactive'"`
+}
+
+func (SchemaDiffUser) TableName() string {
+	return "schema_diff_users"
+}
+
+func TestMigratorPlan(t *testing.T) {
+	DB.DropTableIfExists("schema_diff_users")
+
+	plan, err := DB.Migrator().Plan(&SchemaDiffUserV1{})
+	if err != nil {
+		t.Fatalf("Failed to plan, got error %v", err)
+	}
+
+	if len(plan) != 2 || plan[0].Kind != gorm.CreateTableChange || plan[1].Kind != gorm.AddIndexChange || plan.Destructive() {
+		t.Fatalf("Missing table should be created with its indexes, but got %v", plan.SQL())
+	}
+
+	if err := DB.Migrator().Apply(plan); err != nil {
+		t.Fatalf("Failed to apply plan, got error %v", err)
+	}
+
+	if plan, err := DB.Migrator().Plan(&SchemaDiffUserV1{}); err != nil || len(plan) != 0 {
+		t.Errorf("Nothing should be planned after applying the plan, but got %v, %v", plan.SQL(), err)
+	}
+
+	plan, err = DB.Migrator().Plan(&SchemaDiffUser{})
+	if err != nil {
+		t.Fatalf("Failed to plan, got error %v", err)
+	}
+
+	var kinds, names []string
+	for _, change := range plan {
+		kinds = append(kinds, string(change.Kind))
+		names = append(names, change.Name)
+	}
+
+	expectedKinds := []string{"AddColumn", "AlterColumnType", "AlterColumnNull", "AlterColumnDefault", "DropIndex", "AddIndex", "DropColumn"}
+	expectedNames := []string{"email", "name", "name", "status", "idx_schema_diff_nickname", "idx_schema_diff_email", "legacy"}
+	if !reflect.DeepEqual(kinds, expectedKinds) || !reflect.DeepEqual(names, expectedNames) {
+		t.Fatalf("Changes should be planned in order, but got %v %v", kinds, names)
+	}
+
+	if !plan.Destructive() || !plan[1].Destructive || plan[2].Destructive {
+		t.Errorf("Changing types and dropping should be destructive")
+	}
+
+	var indexChanges gorm.SchemaPlan
+	for _, change := range plan {
+		if change.Kind == gorm.AddColumnChange || change.Kind == gorm.AddIndexChange || change.Kind == gorm.DropIndexChange {
+			indexChanges = append(indexChanges, change)
+		}
+	}
+
+	if err := DB.Migrator().Apply(indexChanges); err != gorm.ErrDestructiveChange {
+		t.Errorf("Destructive changes should be refused, but got %v", err)
+	}
+
+	if err := DB.Set("gorm:allow_destructive_migration", true).Migrator().Apply(indexChanges); err != nil {
+		t.Fatalf("Failed to apply plan, got error %v", err)
+	}
+
+	scope := DB.NewScope(&SchemaDiffUser{})
+	if !scope.Dialect().HasColumn("schema_diff_users", "email") || !scope.Dialect().HasIndex("schema_diff_users", "idx_schema_diff_email") || scope.Dialect().HasIndex("schema_diff_users", "idx_schema_diff_nickname") {
+		t.Errorf("Changes should be applied")
+	}
+}
+
+type PlanWidgetV1 struct {
+	ID   uint
+	Name string `gorm:"size:100"`
+}
+
+func (PlanWidgetV1) TableName() string {
+	return "plan_widgets"
+}
+
+type PlanWidget struct {
+	ID   uint
+	Name string `gorm:"size:200;not null;default:'gear'"`
+}
+
+func (PlanWidget) TableName() string {
+	return "plan_widgets"
+}
+
+func TestMigratorApplyAlterColumns(t *testing.T) {
+	DB.DropTableIfExists("plan_widgets")
+	DB.AutoMigrate(&PlanWidgetV1{})
+	DB.Create(&PlanWidgetV1{Name: "wheel"})
+
+	plan, err := DB.Migrator().Plan(&PlanWidget{})
+	if err != nil || len(plan) != 3 {
+		t.Fatalf("Type, nullability and default value should be planned, but got %v, %v", plan.SQL(), err)
+	}
+
+	if err := DB.Set("gorm:allow_destructive_migration", true).Migrator().Apply(plan); err != nil {
+		t.Fatalf("Failed to apply plan, got error %v", err)
+	}
+
+	columnTypes, _ := DB.Migrator().ColumnTypes(&PlanWidget{})
+	for _, columnType := range columnTypes {
+		if columnType.Name == "name" && (columnType.Length != 200 || columnType.Nullable || !columnType.DefaultValue.Valid) {
+			t.Errorf("Column name should be altered, but got %#v", columnType)
+		}
+	}
+
+	if plan, err := DB.Migrator().Plan(&PlanWidget{}); err != nil || len(plan) != 0 {
+		t.Errorf("Nothing should be planned after applying the plan, but got %v, %v", plan.SQL(), err)
+	}
+
+	var widget PlanWidget
+	if DB.First(&widget).Error != nil || widget.Name != "wheel" {
+		t.Errorf("Rows should be kept after altering columns, but got %#v", widget)
+	}
+	DB.DropTableIfExists("plan_widgets")
+}
+
+func TestMigratorIntrospection(t *testing.T) {
+	DB.DropTableIfExists("introspection_pets", "introspection_owners")
+	DB.Exec("CREATE TABLE introspection_owners (id integer PRIMARY KEY, name varchar(100) NOT NULL DEFAULT 'x', nickname varchar(50))")
+	DB.Exec("CREATE TABLE introspection_pets (id integer PRIMARY KEY, owner_id integer, name varchar(100), CONSTRAINT fk_pets_owner FOREIGN KEY (owner_id) REFERENCES introspection_owners(id) ON DELETE CASCADE)")
+	DB.Exec("CREATE UNIQUE INDEX idx_introspection_pets_name ON introspection_pets(owner_id, name)")
+
+	tables, err := DB.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("Failed to get tables, got error %v", err)
+	}
+
+	var found int
+	for _, table := range tables {
+		if table == "introspection_owners" || table == "introspection_pets" {
+			found++
+		}
+	}
+	if found != 2 {
+		t.Errorf("Tables should be returned, but got %v", tables)
+	}
+
+	columnTypes, err := DB.Migrator().ColumnTypes("introspection_owners")
+	if err != nil || len(columnTypes) != 3 {
+		t.Fatalf("Failed to get column types, got %v, %v", columnTypes, err)
+	}
+
+	if id := columnTypes[0]; id.Name != "id" || !id.PrimaryKey {
+		t.Errorf("id should be the primary key, but got %#v", id)
+	}
+
+	if name := columnTypes[1]; name.Name != "name" || name.Nullable || name.Length != 100 || !name.DefaultValue.Valid || name.PrimaryKey {
+		t.Errorf("name should be a not null varchar(100) with default value, but got %#v", name)
+	}
+
+	if nickname := columnTypes[2]; nickname.Name != "nickname" || !nickname.Nullable || nickname.Length != 50 || nickname.DefaultValue.Valid {
+		t.Errorf("nickname should be a nullable varchar(50), but got %#v", nickname)
+	}
+
+	indexes, err := DB.Migrator().GetIndexes("introspection_pets")
+	if err != nil {
+		t.Fatalf("Failed to get indexes, got error %v", err)
+	}
+
+	var uniqueIndex *gorm.Index
+	for idx := range indexes {
+		if indexes[idx].Name == "idx_introspection_pets_name" {
+			uniqueIndex = &indexes[idx]
+		}
+	}
+	if uniqueIndex == nil || !uniqueIndex.Unique || !reflect.DeepEqual(uniqueIndex.Columns, []string{"owner_id", "name"}) {
+		t.Errorf("Unique index should be returned with its columns in order, but got %v", indexes)
+	}
+
+	foreignKeys, err := DB.Migrator().GetForeignKeys("introspection_pets")
+	if err != nil || len(foreignKeys) != 1 {
+		t.Fatalf("Failed to get foreign keys, got %v, %v", foreignKeys, err)
+	}
+
+	expected := gorm.ForeignKey{Name: "fk_pets_owner", Columns: []string{"owner_id"}, ReferenceTable: "introspection_owners", ReferenceColumns: []string{"id"}, OnDelete: "CASCADE"}
+	foreignKey := foreignKeys[0]
+	foreignKey.OnUpdate = ""
+	if !reflect.DeepEqual(foreignKey, expected) {
+		t.Errorf("Foreign key should be returned, but got %#v", foreignKeys[0])
+	}
+
+	DB.DropTableIfExists("introspection_pets", "introspection_owners")
+}
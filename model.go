@@ -12,3 +12,11 @@ type Model struct {
 	UpdatedAt time.Time
 	DeletedAt *time.Time `sql:"index"`
 }
+
+// Version optimistic locking version, same as tagging an integer field with `version`. Updating a record will check its
+// version and increase it, ErrStaleObject will be returned if the record has been changed, skip it with `gorm:skip_version_check`
+//    type User struct {
+//      ID      uint
+//      Version gorm.Version
+//    }
+type Version int64
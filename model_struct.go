@@ -8,8 +8,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/jinzhu/inflection"
 )
 
 // DefaultTableNameHandler default table name handler
@@ -17,25 +15,31 @@ var DefaultTableNameHandler = func(db *DB, defaultTableName string) string {
 	return defaultTableName
 }
 
+// modelStructKey model structs are cached per naming strategy, as names of them differ
+type modelStructKey struct {
+	namingStrategy interface{}
+	modelType      reflect.Type
+}
+
 type safeModelStructsMap struct {
-	m map[reflect.Type]*ModelStruct
+	m map[modelStructKey]*ModelStruct
 	l *sync.RWMutex
 }
 
-func (s *safeModelStructsMap) Set(key reflect.Type, value *ModelStruct) {
+func (s *safeModelStructsMap) Set(key modelStructKey, value *ModelStruct) {
 	s.l.Lock()
 	defer s.l.Unlock()
 	s.m[key] = value
 }
 
-func (s *safeModelStructsMap) Get(key reflect.Type) *ModelStruct {
+func (s *safeModelStructsMap) Get(key modelStructKey) *ModelStruct {
 	s.l.RLock()
 	defer s.l.RUnlock()
 	return s.m[key]
 }
 
 func newModelStructsMap() *safeModelStructsMap {
-	return &safeModelStructsMap{l: new(sync.RWMutex), m: make(map[reflect.Type]*ModelStruct)}
+	return &safeModelStructsMap{l: new(sync.RWMutex), m: make(map[modelStructKey]*ModelStruct)}
 }
 
 var modelStructsMap = newModelStructsMap()
@@ -55,11 +59,7 @@ func (s *ModelStruct) TableName(db *DB) string {
 		if tabler, ok := reflect.New(s.ModelType).Interface().(tabler); ok {
 			s.defaultTableName = tabler.TableName()
 		} else {
-			tableName := ToDBName(s.ModelType.Name())
-			if db == nil || !db.parent.singularTable {
-				tableName = inflection.Plural(tableName)
-			}
-			s.defaultTableName = tableName
+			s.defaultTableName = db.NamingStrategy().TableName(s.ModelType.Name())
 		}
 	}
 
@@ -111,6 +111,70 @@ func (structField *StructField) clone() *StructField {
 	return clone
 }
 
+// writable check the `<-` tag of the field, `<-:create` only allows creating, `<-:update` only allows updating, `<-:false` is read only
+func (structField *StructField) writable(action string) bool {
+	permission, ok := structField.TagSettings["<-"]
+	if !ok {
+		return true
+	}
+
+	switch strings.ToLower(strings.TrimSpace(permission)) {
+	case "false":
+		return false
+	case "create", "update":
+		return strings.EqualFold(strings.TrimSpace(permission), action)
+	}
+	return true
+}
+
+// readable check the `->` tag of the field, `->:false` is never read from database
+func (structField *StructField) readable() bool {
+	permission, ok := structField.TagSettings["->"]
+	return !ok || !strings.EqualFold(strings.TrimSpace(permission), "false")
+}
+
+// ignoreMigration check the `-:migration` tag of the field, the field is mapped but never migrated
+func (structField *StructField) ignoreMigration() bool {
+	return structField.IsIgnored || strings.EqualFold(strings.TrimSpace(structField.TagSettings["-"]), "migration")
+}
+
+// autoTime check the `autoCreateTime` or `autoUpdateTime` tag of the field and return its unit, `milli`, `nano` or empty for seconds,
+// fields named `CreatedAt` and `UpdatedAt` are tracked by default, they could be disabled with `autoCreateTime:false`
+func (structField *StructField) autoTime(tag string) (unit string, ok bool) {
+	value, ok := structField.TagSettings[tag]
+	if !ok {
+		return "", (tag == "AUTOCREATETIME" && structField.Name == "CreatedAt") || (tag == "AUTOUPDATETIME" && structField.Name == "UpdatedAt")
+	}
+
+	switch unit = strings.ToLower(strings.TrimSpace(value)); unit {
+	case "false":
+		return "", false
+	case "milli", "nano":
+		return unit, true
+	}
+	return "", true
+}
+
+// autoTimeValue return the value of auto timestamp fields, integer fields are set to unix time of the unit
+func (structField *StructField) autoTimeValue(now time.Time, unit string) interface{} {
+	fieldType := structField.Struct.Type
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch unit {
+		case "nano":
+			return now.UnixNano()
+		case "milli":
+			return now.UnixNano() / int64(time.Millisecond)
+		}
+		return now.Unix()
+	}
+	return now
+}
+
 // Relationship described the relationship between models
 type Relationship struct {
 	Kind                         string
@@ -142,7 +206,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 	}
 
 	reflectType := reflect.ValueOf(scope.Value).Type()
-	for reflectType.Kind() == reflect.Slice || reflectType.Kind() == reflect.Ptr {
+	for reflectType.Kind() == reflect.Slice || reflectType.Kind() == reflect.Array || reflectType.Kind() == reflect.Ptr {
 		reflectType = reflectType.Elem()
 	}
 
@@ -152,12 +216,19 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 	}
 
 	// Get Cached model struct
-	if value := modelStructsMap.Get(reflectType); value != nil {
+	namingStrategy := scope.db.NamingStrategy()
+	cacheKey := modelStructKey{namingStrategy: namingStrategyKey(namingStrategy), modelType: reflectType}
+	if value := modelStructsMap.Get(cacheKey); value != nil {
 		return value
 	}
 
 	modelStruct.ModelType = reflectType
 
+	var tableName string
+	if scope.db != nil {
+		tableName = modelStruct.TableName(scope.db)
+	}
+
 	// Get all fields
 	for i := 0; i < reflectType.NumField(); i++ {
 		if fieldStruct := reflectType.Field(i); ast.IsExported(fieldStruct.Name) {
@@ -169,8 +240,8 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 				TagSettings: parseTagSetting(fieldStruct.Tag),
 			}
 
-			// is ignored field
-			if _, ok := field.TagSettings["-"]; ok {
+			// is ignored field, fields tagged with `-:migration` are only ignored when migrating
+			if _, ok := field.TagSettings["-"]; ok && !field.ignoreMigration() {
 				field.IsIgnored = true
 			} else {
 				if _, ok := field.TagSettings["PRIMARY_KEY"]; ok {
@@ -192,7 +263,10 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 				}
 
 				fieldValue := reflect.New(indirectType).Interface()
-				if _, isScanner := fieldValue.(sql.Scanner); isScanner {
+				if _, ok := field.TagSettings["SERIALIZER"]; ok {
+					// is encoded with serializer
+					field.IsNormal = true
+				} else if _, isScanner := fieldValue.(sql.Scanner); isScanner {
 					// is scanner
 					field.IsScanner, field.IsNormal = true, true
 					if indirectType.Kind() == reflect.Struct {
@@ -290,7 +364,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 													// if defined join table's foreign key
 													relationship.ForeignDBNames = append(relationship.ForeignDBNames, joinTableDBNames[idx])
 												} else {
-													defaultJointableForeignKey := ToDBName(reflectType.Name()) + "_" + foreignField.DBName
+													defaultJointableForeignKey := namingStrategy.ColumnName(many2many, reflectType.Name()) + "_" + foreignField.DBName
 													relationship.ForeignDBNames = append(relationship.ForeignDBNames, defaultJointableForeignKey)
 												}
 											}
@@ -321,7 +395,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 													relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, associationJoinTableDBNames[idx])
 												} else {
 													// join table foreign keys for association
-													joinTableDBName := ToDBName(elemType.Name()) + "_" + field.DBName
+													joinTableDBName := namingStrategy.ColumnName(many2many, elemType.Name()) + "_" + field.DBName
 													relationship.AssociationForeignDBNames = append(relationship.AssociationForeignDBNames, joinTableDBName)
 												}
 											}
@@ -329,7 +403,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 									}
 
 									joinTableHandler := JoinTableHandler{}
-									joinTableHandler.Setup(relationship, many2many, reflectType, elemType)
+									joinTableHandler.Setup(relationship, namingStrategy.JoinTableName(many2many), reflectType, elemType)
 									relationship.JoinTableHandler = &joinTableHandler
 									field.Relationship = relationship
 								} else {
@@ -587,7 +661,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 			if value, ok := field.TagSettings["COLUMN"]; ok {
 				field.DBName = value
 			} else {
-				field.DBName = ToDBName(fieldStruct.Name)
+				field.DBName = namingStrategy.ColumnName(tableName, fieldStruct.Name)
 			}
 
 			modelStruct.StructFields = append(modelStruct.StructFields, field)
@@ -601,7 +675,7 @@ func (scope *Scope) GetModelStruct() *ModelStruct {
 		}
 	}
 
-	modelStructsMap.Set(reflectType, &modelStruct)
+	modelStructsMap.Set(cacheKey, &modelStruct)
 
 	return &modelStruct
 }
@@ -0,0 +1,142 @@
+package gorm
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"reflect"
+	"regexp"
+	"unicode"
+
+	"github.com/jinzhu/inflection"
+)
+
+// NamingStrategy decide names of tables, columns, join tables, indexes and foreign keys, it is set per db with `SetNamingStrategy`.
+// Model structs are cached per naming strategy, so it should be comparable, e.g. a struct value with comparable fields or a pointer
+type NamingStrategy interface {
+	// TableName return table name of the struct name
+	TableName(name string) string
+	// ColumnName return column name of the struct field name
+	ColumnName(table, name string) string
+	// JoinTableName return table name of the join table defined with `many2many`
+	JoinTableName(name string) string
+	// IndexName return index name of the column, kind is `idx` for indexes and `uix` for unique indexes
+	IndexName(kind, table, column string) string
+	// RelationshipFKName return foreign key constraint name of the column referencing dest
+	RelationshipFKName(table, column, dest string) string
+}
+
+// DefaultNamingStrategy the default naming strategy, names are snake cased and table names are pluralized by default
+type DefaultNamingStrategy struct {
+	TablePrefix   string
+	SingularTable bool
+	CamelCase     bool
+}
+
+var keyNameRegexp = regexp.MustCompile("[^a-zA-Z0-9]+")
+
+// TableName return table name of the struct name
+func (ns DefaultNamingStrategy) TableName(name string) string {
+	tableName := ns.toName(name)
+	if !ns.SingularTable {
+		tableName = inflection.Plural(tableName)
+	}
+	return ns.TablePrefix + tableName
+}
+
+// ColumnName return column name of the struct field name
+func (ns DefaultNamingStrategy) ColumnName(table, name string) string {
+	return ns.toName(name)
+}
+
+// JoinTableName return table name of the join table with prefix
+func (ns DefaultNamingStrategy) JoinTableName(name string) string {
+	return ns.TablePrefix + name
+}
+
+// IndexName return index name like `idx_users_name`
+func (ns DefaultNamingStrategy) IndexName(kind, table, column string) string {
+	return keyNameRegexp.ReplaceAllString(fmt.Sprintf("%s_%s_%s", kind, table, column), "_")
+}
+
+// RelationshipFKName return foreign key constraint name like `users_company_id_companies_id_foreign`
+func (ns DefaultNamingStrategy) RelationshipFKName(table, column, dest string) string {
+	return keyNameRegexp.ReplaceAllString(fmt.Sprintf("%s_%s_%s_foreign", table, column, dest), "_")
+}
+
+func (ns DefaultNamingStrategy) toName(name string) string {
+	if !ns.CamelCase {
+		return ToDBName(name)
+	}
+
+	// lower the leading upper letters, but keep the one starting next word, e.g. `HTTPServer` to `httpServer`
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// NamingStrategy return naming strategy of current db
+func (s *DB) NamingStrategy() NamingStrategy {
+	if s != nil && s.parent != nil && s.parent.namingStrategy != nil {
+		return s.parent.namingStrategy
+	}
+	return DefaultNamingStrategy{}
+}
+
+// SetNamingStrategy set naming strategy of current db
+//     db.SetNamingStrategy(gorm.DefaultNamingStrategy{TablePrefix: "app_", SingularTable: true})
+func (s *DB) SetNamingStrategy(namingStrategy NamingStrategy) *DB {
+	s.parent.namingStrategy = namingStrategy
+	return s
+}
+
+// namingStrategyKey return the key to cache model structs of the naming strategy
+func namingStrategyKey(namingStrategy NamingStrategy) interface{} {
+	if namingStrategy == nil {
+		return DefaultNamingStrategy{}
+	} else if !reflect.TypeOf(namingStrategy).Comparable() {
+		return fmt.Sprintf("%T%#v", namingStrategy, namingStrategy)
+	}
+	return namingStrategy
+}
+
+// indexKeyName return name of the index on the column, names of the default naming strategy are built by the dialect's
+// BuildKeyName to keep names created before, e.g. long names are shortened by mysql with leading characters of the column
+func (scope *Scope) indexKeyName(kind, table, column string) string {
+	if isDefaultNamingStrategy(scope.db.NamingStrategy()) {
+		return scope.Dialect().BuildKeyName(kind, table, column)
+	}
+	return limitKeyName(scope.Dialect(), scope.db.NamingStrategy().IndexName(kind, table, column))
+}
+
+// relationshipFKName return name of the foreign key constraint of the column referencing dest, names of the default naming
+// strategy are built by the dialect's BuildKeyName like indexKeyName
+func (scope *Scope) relationshipFKName(table, column, dest string) string {
+	if isDefaultNamingStrategy(scope.db.NamingStrategy()) {
+		return scope.Dialect().BuildKeyName(table, column, dest, "foreign")
+	}
+	return limitKeyName(scope.Dialect(), scope.db.NamingStrategy().RelationshipFKName(table, column, dest))
+}
+
+func isDefaultNamingStrategy(namingStrategy NamingStrategy) bool {
+	switch namingStrategy.(type) {
+	case DefaultNamingStrategy, *DefaultNamingStrategy:
+		return true
+	}
+	return false
+}
+
+// limitKeyName shorten key names longer than the limit of the dialect, refer `KeyNameLengthDialect`
+func limitKeyName(dialect Dialect, name string) string {
+	if dialect, ok := dialect.(KeyNameLengthDialect); ok {
+		if maxLength, runes := dialect.MaxKeyNameLength(), []rune(name); len(runes) > maxLength && maxLength > 40 {
+			// sha1 is 40 characters, keep leading characters of the name
+			return fmt.Sprintf("%s%x", string(runes[:maxLength-40]), sha1.Sum([]byte(name)))
+		}
+	}
+	return name
+}
@@ -0,0 +1,97 @@
+package gorm_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+type NamingUser struct {
+	ID       uint
+	FullName string      `gorm:"index"`
+	Tags     []NamingTag `gorm:"many2many:naming_user_tags"`
+}
+
+type NamingTag struct {
+	ID   uint
+	Name string
+}
+
+func TestDefaultNamingStrategy(t *testing.T) {
+	cases := []struct {
+		namingStrategy gorm.DefaultNamingStrategy
+		table, column  string
+	}{
+		{gorm.DefaultNamingStrategy{}, "user_profiles", "http_server_id"},
+		{gorm.DefaultNamingStrategy{TablePrefix: "app_", SingularTable: true}, "app_user_profile", "http_server_id"},
+		{gorm.DefaultNamingStrategy{CamelCase: true}, "userProfiles", "httpServerID"},
+	}
+
+	for _, c := range cases {
+		if table := c.namingStrategy.TableName("UserProfile"); table != c.table {
+			t.Errorf("table name should be %v, but got %v", c.table, table)
+		}
+
+		if column := c.namingStrategy.ColumnName("", "HTTPServerID"); column != c.column {
+			t.Errorf("column name should be %v, but got %v", c.column, column)
+		}
+	}
+
+	namingStrategy := gorm.DefaultNamingStrategy{TablePrefix: "app_"}
+	if name := namingStrategy.JoinTableName("user_languages"); name != "app_user_languages" {
+		t.Errorf("join table name should be prefixed, but got %v", name)
+	}
+
+	if name := namingStrategy.IndexName("uix", "users", "email"); name != "uix_users_email" {
+		t.Errorf("unexpected index name %v", name)
+	}
+
+	if name := namingStrategy.RelationshipFKName("users", "company_id", "companies(id)"); name != "users_company_id_companies_id_foreign" {
+		t.Errorf("unexpected foreign key name %v", name)
+	}
+}
+
+func TestNamingStrategyPerDB(t *testing.T) {
+	path := filepath.Join(os.TempDir(), "gorm_naming.db")
+	os.Remove(path)
+
+	db, err := gorm.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open %v, got %v", path, err)
+	}
+	defer db.Close()
+
+	db.SetNamingStrategy(gorm.DefaultNamingStrategy{TablePrefix: "app_", CamelCase: true})
+	if err := db.AutoMigrate(&NamingUser{}, &NamingTag{}).Error; err != nil {
+		t.Fatalf("no error should happen when migrating, but got %v", err)
+	}
+
+	for _, table := range []string{"app_namingUsers", "app_namingTags", "app_naming_user_tags"} {
+		if !db.Dialect().HasTable(table) {
+			t.Errorf("table %v should be created with the naming strategy", table)
+		}
+	}
+
+	if !db.Dialect().HasColumn("app_namingUsers", "fullName") || !db.Dialect().HasColumn("app_naming_user_tags", "namingUser_id") {
+		t.Errorf("columns should be named with the naming strategy")
+	}
+
+	if !db.Dialect().HasIndex("app_namingUsers", "idx_app_namingUsers_fullName") {
+		t.Errorf("indexes should be named with the naming strategy")
+	}
+
+	user := NamingUser{FullName: "naming", Tags: []NamingTag{{Name: "tag"}}}
+	db.Create(&user)
+
+	var result NamingUser
+	if err := db.Preload("Tags").Where(&NamingUser{FullName: "naming"}).First(&result).Error; err != nil || len(result.Tags) != 1 {
+		t.Errorf("should find records with the naming strategy, but got %v, %#v", err, result)
+	}
+
+	scope := DB.NewScope(&NamingUser{})
+	if field, _ := scope.FieldByName("FullName"); scope.TableName() != "naming_users" || field.DBName != "full_name" {
+		t.Errorf("naming strategy of other dbs should not be affected, but got %v, %v", scope.TableName(), field.DBName)
+	}
+}
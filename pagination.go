@@ -0,0 +1,250 @@
+package gorm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// pagination keyset pagination of a query, refer `DB.Paginate`, it is shared by cloned dbs so shouldn't be changed when querying,
+// cursors of adjacent pages are set to the result db as `gorm:pagination_cursors`
+type pagination struct {
+	cursor       string
+	limit        int
+	orderColumns []string
+}
+
+// paginationCursor is encoded to the opaque cursor, records after (or before) its values are in the page
+type paginationCursor struct {
+	Before bool              `json:"before,omitempty"`
+	Values []json.RawMessage `json:"values"`
+}
+
+type paginationColumn struct {
+	name       string
+	quotedName string
+	desc       bool
+	fieldType  reflect.Type
+}
+
+func (p *pagination) columns(scope *Scope) (columns []paginationColumn) {
+	orderColumns := p.orderColumns
+	if len(orderColumns) == 0 {
+		if primaryField := scope.PrimaryField(); primaryField != nil {
+			orderColumns = []string{primaryField.DBName}
+		}
+	}
+
+	for _, orderColumn := range orderColumns {
+		var (
+			parts  = strings.Fields(orderColumn)
+			column = paginationColumn{}
+		)
+
+		if len(parts) == 0 {
+			continue
+		}
+
+		if len(parts) > 1 {
+			column.desc = strings.EqualFold(parts[1], "desc")
+		}
+
+		if strings.Contains(parts[0], ".") {
+			column.quotedName = scope.Quote(parts[0])
+		} else {
+			column.quotedName = fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(parts[0]))
+		}
+
+		column.name = parts[0][strings.LastIndex(parts[0], ".")+1:]
+		if field, ok := scope.FieldByName(column.name); ok {
+			column.name = field.DBName
+			column.fieldType = field.Struct.Type
+		}
+		columns = append(columns, column)
+	}
+	return
+}
+
+// prepare add order, limit and keyset conditions of the page to the query
+func (p *pagination) prepare(scope *Scope) {
+	var (
+		cursor  paginationCursor
+		columns = p.columns(scope)
+	)
+
+	if len(columns) == 0 {
+		scope.Err(errors.New("order columns required to paginate"))
+		return
+	} else if p.limit <= 0 {
+		scope.Err(errors.New("pagination limit should be greater than zero"))
+		return
+	}
+
+	if p.cursor != "" {
+		data, err := base64.RawURLEncoding.DecodeString(p.cursor)
+		if err != nil || json.Unmarshal(data, &cursor) != nil || len(cursor.Values) != len(columns) {
+			scope.Err(ErrInvalidCursor)
+			return
+		}
+	}
+
+	for idx, column := range columns {
+		if column.desc != cursor.Before {
+			scope.Search.Order(column.quotedName+" DESC", idx == 0)
+		} else {
+			scope.Search.Order(column.quotedName+" ASC", idx == 0)
+		}
+	}
+	// query one more record to know whether there are more pages
+	scope.Search.Limit(p.limit + 1)
+
+	if p.cursor == "" {
+		return
+	}
+
+	var values []interface{}
+
+	for idx, column := range columns {
+		var value interface{}
+		if column.fieldType != nil {
+			ptr := reflect.New(column.fieldType)
+			if json.Unmarshal(cursor.Values[idx], ptr.Interface()) != nil {
+				scope.Err(ErrInvalidCursor)
+				return
+			}
+			value = ptr.Elem().Interface()
+		} else if json.Unmarshal(cursor.Values[idx], &value) != nil {
+			scope.Err(ErrInvalidCursor)
+			return
+		}
+
+		values = append(values, value)
+	}
+
+	var quotedNames, comparators []string
+	for _, column := range columns {
+		quotedNames = append(quotedNames, column.quotedName)
+		if column.desc != cursor.Before {
+			comparators = append(comparators, "<")
+		} else {
+			comparators = append(comparators, ">")
+		}
+	}
+
+	condition, vars := keysetCondition(scope.Dialect(), quotedNames, comparators, values)
+	scope.Search.Where(condition, vars...)
+}
+
+// keysetCondition return the condition of records after values in the order of columns, comparators are `>` or `<` of each column,
+// it is `(a, b) > (?, ?)` if all comparators are the same and the dialect supports row values
+func keysetCondition(dialect Dialect, quotedNames []string, comparators []string, values []interface{}) (string, []interface{}) {
+	sameDirection := true
+	for _, comparator := range comparators {
+		sameDirection = sameDirection && comparator == comparators[0]
+	}
+
+	if sameDirection && supportRowValues(dialect) {
+		placeholders := make([]string, len(quotedNames))
+		for idx := range placeholders {
+			placeholders[idx] = "?"
+		}
+		return fmt.Sprintf("(%v) %v (%v)", strings.Join(quotedNames, ", "), comparators[0], strings.Join(placeholders, ", ")), values
+	}
+
+	// expand to `a > ? OR (a = ? AND b > ?)` for mixed orders or dialects without row value comparisons
+	var (
+		conditions []string
+		vars       []interface{}
+	)
+
+	for idx, quotedName := range quotedNames {
+		var condition []string
+		for i := 0; i < idx; i++ {
+			condition = append(condition, fmt.Sprintf("%v = ?", quotedNames[i]))
+			vars = append(vars, values[i])
+		}
+		condition = append(condition, fmt.Sprintf("%v %v ?", quotedName, comparators[idx]))
+		vars = append(vars, values[idx])
+		conditions = append(conditions, "("+strings.Join(condition, " AND ")+")")
+	}
+	return strings.Join(conditions, " OR "), vars
+}
+
+// setCursors drop the extra record and restore the order of records queried backward, then set cursors of adjacent pages
+func (p *pagination) setCursors(scope *Scope, results reflect.Value) {
+	var nextCursor, prevCursor string
+	defer func() {
+		scope.db.InstantSet("gorm:pagination_cursors", [2]string{nextCursor, prevCursor})
+	}()
+
+	var (
+		before  bool
+		hasMore = results.Len() > p.limit
+		columns = p.columns(scope)
+	)
+
+	if hasMore {
+		results.Set(results.Slice(0, p.limit))
+		scope.db.RowsAffected = int64(p.limit)
+	}
+	length := results.Len()
+
+	if p.cursor != "" {
+		data, _ := base64.RawURLEncoding.DecodeString(p.cursor)
+		var cursor paginationCursor
+		json.Unmarshal(data, &cursor)
+		before = cursor.Before
+	}
+
+	if before {
+		swap := reflect.Swapper(results.Interface())
+		for i, j := 0, length-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if length == 0 {
+		return
+	}
+
+	if hasMore || before {
+		nextCursor = p.encodeCursor(scope, columns, results.Index(length-1), false)
+	}
+
+	if (hasMore && before) || (!before && p.cursor != "") {
+		prevCursor = p.encodeCursor(scope, columns, results.Index(0), true)
+	}
+}
+
+func (p *pagination) encodeCursor(scope *Scope, columns []paginationColumn, record reflect.Value, before bool) string {
+	if record.Kind() != reflect.Ptr {
+		record = record.Addr()
+	}
+
+	var (
+		recordScope = scope.New(record.Interface())
+		cursor      = paginationCursor{Before: before}
+	)
+
+	for _, column := range columns {
+		var value interface{}
+		if field, ok := recordScope.FieldByName(column.name); ok {
+			value = field.Field.Interface()
+		}
+
+		data, err := json.Marshal(value)
+		if scope.Err(err) != nil {
+			return ""
+		}
+		cursor.Values = append(cursor.Values, data)
+	}
+
+	data, err := json.Marshal(cursor)
+	if scope.Err(err) != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,159 @@
+package gorm_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+type PaginatedUser struct {
+	ID    uint
+	Name  string
+	Age   int
+	Group string
+}
+
+func paginatedAges(users []PaginatedUser) string {
+	var ages []string
+	for _, user := range users {
+		ages = append(ages, fmt.Sprint(user.Age))
+	}
+	return strings.Join(ages, ",")
+}
+
+func TestPaginate(t *testing.T) {
+	DB.DropTableIfExists(&PaginatedUser{})
+	DB.AutoMigrate(&PaginatedUser{})
+
+	for i := 1; i <= 5; i++ {
+		DB.Save(&PaginatedUser{Name: "paginate", Age: i})
+	}
+	DB.Save(&PaginatedUser{Name: "other", Age: 6})
+
+	var users []PaginatedUser
+	tx := DB.Where("name = ?", "paginate")
+
+	result := tx.Paginate("", 2).Find(&users)
+	next, prev := result.Cursors()
+	if result.Error != nil || paginatedAges(users) != "1,2" || next == "" || prev != "" {
+		t.Fatalf("first page should be found, but got %v, %v, %q, %q", result.Error, paginatedAges(users), next, prev)
+	}
+
+	result = tx.Paginate(next, 2).Find(&users)
+	next, prev = result.Cursors()
+	if paginatedAges(users) != "3,4" || next == "" || prev == "" {
+		t.Fatalf("second page should be found, but got %v, %q, %q", paginatedAges(users), next, prev)
+	}
+
+	result = tx.Paginate(next, 2).Find(&users)
+	lastNext, lastPrev := result.Cursors()
+	if paginatedAges(users) != "5" || lastNext != "" || lastPrev == "" {
+		t.Fatalf("last page should be found, but got %v, %q, %q", paginatedAges(users), lastNext, lastPrev)
+	}
+
+	result = tx.Paginate(lastPrev, 2).Find(&users)
+	if paginatedAges(users) != "3,4" {
+		t.Errorf("previous page should be found in order, but got %v", paginatedAges(users))
+	}
+
+	_, prev = result.Cursors()
+	result = tx.Paginate(prev, 2).Find(&users)
+	next, prev = result.Cursors()
+	if paginatedAges(users) != "1,2" || next == "" || prev != "" {
+		t.Errorf("first page should be found backward, but got %v, %q, %q", paginatedAges(users), next, prev)
+	}
+
+	if err := tx.Paginate("invalid", 2).Find(&users).Error; err != gorm.ErrInvalidCursor {
+		t.Errorf("should return ErrInvalidCursor with invalid cursor, but got %v", err)
+	}
+}
+
+func TestPaginateWithMixedOrders(t *testing.T) {
+	DB.DropTableIfExists(&PaginatedUser{})
+	DB.AutoMigrate(&PaginatedUser{})
+
+	for i := 1; i <= 6; i++ {
+		DB.Save(&PaginatedUser{Name: "paginate", Age: i, Group: fmt.Sprint("group", i%2)})
+	}
+
+	var (
+		users     []PaginatedUser
+		pages     []string
+		next      string
+		firstNext string
+	)
+	for {
+		result := DB.Paginate(next, 2, "group", "age desc").Find(&users)
+		if result.Error != nil {
+			t.Fatalf("no error should happen when paginating, but got %v", result.Error)
+		}
+		pages = append(pages, paginatedAges(users))
+		if next, _ = result.Cursors(); next == "" {
+			break
+		} else if firstNext == "" {
+			firstNext = next
+		}
+	}
+
+	if strings.Join(pages, "|") != "6,4|2,5|3,1" {
+		t.Errorf("should paginate with mixed orders, but got %v", pages)
+	}
+
+	sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Paginate(firstNext, 2, "group", "age desc").Find(&users)
+	})
+	if !strings.Contains(sql, " OR ") {
+		t.Errorf("mixed orders should be compared with expanded conditions, but got %v", sql)
+	}
+
+	sql = DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		next, _ := DB.Paginate("", 2, "group", "age").Find(&users).Cursors()
+		return tx.Paginate(next, 2, "group", "age").Find(&users)
+	})
+	if !strings.Contains(sql, "(\"paginated_users\".\"group\", \"paginated_users\".\"age\") > (") {
+		t.Errorf("same orders should be compared with row values, but got %v", sql)
+	}
+}
+
+func TestPaginateWithSharedDB(t *testing.T) {
+	DB.DropTableIfExists(&PaginatedUser{})
+	DB.AutoMigrate(&PaginatedUser{})
+
+	for i := 1; i <= 5; i++ {
+		DB.Save(&PaginatedUser{Name: "paginate", Age: i})
+	}
+
+	var (
+		wg        sync.WaitGroup
+		firstPage = DB.Where("name = ?", "paginate").Paginate("", 2)
+	)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var users []PaginatedUser
+			result := firstPage.Find(&users)
+			if next, prev := result.Cursors(); result.Error != nil || next == "" || prev != "" {
+				t.Errorf("first page should be found concurrently, but got %v, %q, %q", result.Error, next, prev)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var users []PaginatedUser
+	result := firstPage.Find(&users)
+	next, _ := result.Cursors()
+
+	firstPage.Where("age > ?", 1).Find(&users)
+	if newNext, _ := result.Cursors(); newNext != next {
+		t.Errorf("cursors of a result should not be changed by other queries, but got %q, expects %q", newNext, next)
+	}
+
+	if next, prev := firstPage.Cursors(); next != "" || prev != "" {
+		t.Errorf("cursors should be set to the result rather than the shared db, but got %q, %q", next, prev)
+	}
+}
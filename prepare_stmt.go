@@ -0,0 +1,243 @@
+package gorm
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// PreparedStmtCacheSize the max number of cached prepared statements, least recently used statements will be closed
+var PreparedStmtCacheSize = 200
+
+// preparedStmts cache of prepared statements keyed by SQL, shared by dbs opened with the same `Open`
+type preparedStmts struct {
+	mu    sync.Mutex
+	stmts map[string]*list.Element
+	lru   *list.List
+}
+
+// preparedStmt cached statement, evicted statements are closed once they are released by all executions
+type preparedStmt struct {
+	sql     string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newPreparedStmts() *preparedStmts {
+	return &preparedStmts{stmts: map[string]*list.Element{}, lru: list.New()}
+}
+
+// prepare return the cached statement of the SQL, or prepare it with conn, the statement should be released after executed
+func (s *preparedStmts) prepare(ctx context.Context, conn SQLCommon, query string) (*preparedStmt, error) {
+	if stmt := s.acquire(query); stmt != nil {
+		return stmt, nil
+	}
+
+	var (
+		stmt *sql.Stmt
+		err  error
+	)
+
+	// prepare without locking, so slow statements won't block others
+	if connContext, ok := conn.(SQLCommonContext); ok {
+		stmt, err = connContext.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.Prepare(query)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the same SQL might be prepared concurrently, keep the cached one
+	if elem, ok := s.stmts[query]; ok {
+		stmt.Close()
+		s.lru.MoveToFront(elem)
+		elem.Value.(*preparedStmt).refs++
+		return elem.Value.(*preparedStmt), nil
+	}
+
+	prepared := &preparedStmt{sql: query, stmt: stmt, refs: 1}
+	s.stmts[query] = s.lru.PushFront(prepared)
+	for s.lru.Len() > PreparedStmtCacheSize && s.lru.Len() > 0 {
+		s.evict(s.lru.Back())
+	}
+	return prepared, nil
+}
+
+// acquire return the cached statement of the SQL if exists
+func (s *preparedStmts) acquire(query string) *preparedStmt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.stmts[query]; ok {
+		s.lru.MoveToFront(elem)
+		elem.Value.(*preparedStmt).refs++
+		return elem.Value.(*preparedStmt)
+	}
+	return nil
+}
+
+// release release the statement returned by prepare, close it if evicted and no longer used
+func (s *preparedStmts) release(stmt *preparedStmt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stmt.refs--; stmt.refs == 0 && stmt.evicted {
+		stmt.stmt.Close()
+	}
+}
+
+// evict remove the statement from cache, it is closed if not used, otherwise closed when released
+func (s *preparedStmts) evict(elem *list.Element) {
+	stmt := s.lru.Remove(elem).(*preparedStmt)
+	delete(s.stmts, stmt.sql)
+	stmt.evicted = true
+	if stmt.refs == 0 {
+		stmt.stmt.Close()
+	}
+}
+
+// close close all cached statements, statements being executed are closed when released
+func (s *preparedStmts) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.lru.Len() > 0 {
+		s.evict(s.lru.Back())
+	}
+}
+
+// preparedStmtDB connection that executes SQL with cached prepared statements
+type preparedStmtDB struct {
+	SQLCommon
+	stmts *preparedStmts
+}
+
+func (db *preparedStmtDB) Begin() (*sql.Tx, error) {
+	if conn, ok := db.SQLCommon.(sqlDb); ok {
+		return conn.Begin()
+	}
+	return nil, ErrCantStartTransaction
+}
+
+func (db *preparedStmtDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *preparedStmtDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+func (db *preparedStmtDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+func (db *preparedStmtDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := db.stmts.prepare(ctx, db.SQLCommon, query)
+	if err != nil {
+		return nil, err
+	}
+	defer db.stmts.release(stmt)
+	return stmt.stmt.ExecContext(ctx, args...)
+}
+
+func (db *preparedStmtDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if conn, ok := db.SQLCommon.(SQLCommonContext); ok {
+		return conn.PrepareContext(ctx, query)
+	}
+	return db.SQLCommon.Prepare(query)
+}
+
+func (db *preparedStmtDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := db.stmts.prepare(ctx, db.SQLCommon, query)
+	if err != nil {
+		return nil, err
+	}
+	defer db.stmts.release(stmt)
+	return stmt.stmt.QueryContext(ctx, args...)
+}
+
+func (db *preparedStmtDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	stmt, err := db.stmts.prepare(ctx, db.SQLCommon, query)
+	if err != nil {
+		// *sql.Row can't be created with an error, let the unprepared query report it
+		return queryRowContext(ctx, db.SQLCommon, query, args...)
+	}
+	defer db.stmts.release(stmt)
+	return stmt.stmt.QueryRowContext(ctx, args...)
+}
+
+// preparedStmtTx transaction that executes SQL with cached prepared statements, which are bound to the transaction with `tx.Stmt`
+type preparedStmtTx struct {
+	*sql.Tx
+	db    SQLCommon
+	stmts *preparedStmts
+}
+
+func (tx *preparedStmtTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+func (tx *preparedStmtTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.QueryContext(context.Background(), query, args...)
+}
+
+func (tx *preparedStmtTx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.QueryRowContext(context.Background(), query, args...)
+}
+
+func (tx *preparedStmtTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := tx.stmts.prepare(ctx, tx.db, query)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.stmts.release(stmt)
+	return tx.StmtContext(ctx, stmt.stmt).ExecContext(ctx, args...)
+}
+
+func (tx *preparedStmtTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := tx.stmts.prepare(ctx, tx.db, query)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.stmts.release(stmt)
+	return tx.StmtContext(ctx, stmt.stmt).QueryContext(ctx, args...)
+}
+
+func (tx *preparedStmtTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	stmt, err := tx.stmts.prepare(ctx, tx.db, query)
+	if err != nil {
+		return tx.Tx.QueryRowContext(ctx, query, args...)
+	}
+	defer tx.stmts.release(stmt)
+	return tx.StmtContext(ctx, stmt.stmt).QueryRowContext(ctx, args...)
+}
+
+// withPreparedStmts wrap the connection to execute SQL with cached prepared statements
+func (s *DB) withPreparedStmts(conn SQLCommon) SQLCommon {
+	switch conn := conn.(type) {
+	case *preparedStmtDB, *preparedStmtTx:
+		return conn
+	case *sql.Tx:
+		return &preparedStmtTx{Tx: conn, db: unwrapPreparedStmts(s.parent.db), stmts: s.parent.preparedStmts}
+	default:
+		return &preparedStmtDB{SQLCommon: conn, stmts: s.parent.preparedStmts}
+	}
+}
+
+// unwrapPreparedStmts return the connection wrapped for prepared statements
+func unwrapPreparedStmts(conn SQLCommon) SQLCommon {
+	switch conn := conn.(type) {
+	case *preparedStmtDB:
+		return conn.SQLCommon
+	case *preparedStmtTx:
+		return conn.Tx
+	}
+	return conn
+}
@@ -0,0 +1,228 @@
+package gorm_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type PreparedUser struct {
+	ID   uint
+	Name string
+}
+
+type countingPrepareConn struct {
+	*sql.DB
+	prepares int32
+}
+
+func (conn *countingPrepareConn) Prepare(query string) (*sql.Stmt, error) {
+	atomic.AddInt32(&conn.prepares, 1)
+	return conn.DB.Prepare(query)
+}
+
+func (conn *countingPrepareConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	atomic.AddInt32(&conn.prepares, 1)
+	return conn.DB.PrepareContext(ctx, query)
+}
+
+func openPrepareStmtDB(t *testing.T) (*gorm.DB, *countingPrepareConn) {
+	path := filepath.Join(os.TempDir(), "gorm_prepare_stmt.db")
+	os.Remove(path)
+
+	sqlDB, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open %v, got %v", path, err)
+	}
+
+	conn := &countingPrepareConn{DB: sqlDB}
+	db, err := gorm.Open("sqlite3", conn)
+	if err != nil {
+		t.Fatalf("failed to open %v, got %v", path, err)
+	}
+
+	db.AutoMigrate(&PreparedUser{})
+	return db, conn
+}
+
+func TestPrepareStmt(t *testing.T) {
+	db, conn := openPrepareStmtDB(t)
+	defer db.Close()
+
+	tx := db.PrepareStmt()
+	for i := 0; i < 5; i++ {
+		if err := tx.Create(&PreparedUser{Name: "prepared"}).Error; err != nil {
+			t.Errorf("no error should happen when creating with prepared statements, but got %v", err)
+		}
+	}
+
+	if prepares := atomic.LoadInt32(&conn.prepares); prepares != 1 {
+		t.Errorf("statements should be prepared once, but got %v", prepares)
+	}
+
+	var count int
+	tx.Model(&PreparedUser{}).Where("name = ?", "prepared").Count(&count)
+	if count != 5 {
+		t.Errorf("should find records created with prepared statements, but got %v", count)
+	}
+
+	db.Create(&PreparedUser{Name: "unprepared"})
+	if prepares := atomic.LoadInt32(&conn.prepares); prepares != 2 {
+		t.Errorf("statements should not be prepared out of the session, but got %v", prepares)
+	}
+}
+
+func TestPrepareStmtInTransaction(t *testing.T) {
+	db, conn := openPrepareStmtDB(t)
+	defer db.Close()
+	db.SetPrepareStmt(true)
+
+	db.Create(&PreparedUser{Name: "prepared"})
+
+	tx := db.Begin()
+	tx.Create(&PreparedUser{Name: "prepared"})
+	tx.Rollback()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&PreparedUser{Name: "prepared"}).Error
+	})
+	if err != nil {
+		t.Errorf("no error should happen when creating in transaction, but got %v", err)
+	}
+
+	var count int
+	db.Model(&PreparedUser{}).Where("name = ?", "prepared").Count(&count)
+	if count != 2 {
+		t.Errorf("transactions should work with prepared statements, but got %v records", count)
+	}
+
+	if prepares := atomic.LoadInt32(&conn.prepares); prepares != 2 {
+		t.Errorf("statements should be prepared once and shared by transactions, but got %v", prepares)
+	}
+}
+
+func TestPrepareStmtConcurrently(t *testing.T) {
+	db, _ := openPrepareStmtDB(t)
+	defer db.Close()
+	db.SetPrepareStmt(true)
+
+	defer func(size int) { gorm.PreparedStmtCacheSize = size }(gorm.PreparedStmtCacheSize)
+	gorm.PreparedStmtCacheSize = 2
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var users []PreparedUser
+			if err := db.Where("id > ?", i).Limit(i + 1).Find(&users).Error; err != nil {
+				t.Errorf("no error should happen when querying concurrently, but got %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestPrepareStmtEvictedWhileExecuting(t *testing.T) {
+	db, _ := openPrepareStmtDB(t)
+	defer db.Close()
+	db.SetPrepareStmt(true)
+
+	defer func(size int) { gorm.PreparedStmtCacheSize = size }(gorm.PreparedStmtCacheSize)
+	gorm.PreparedStmtCacheSize = 1
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				var users []PreparedUser
+				if err := db.Where("id > ?", i).Limit(j + 1).Find(&users).Error; err != nil {
+					t.Errorf("statements being executed should not be closed when evicted, but got %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+type blockingPrepareConn struct {
+	*sql.DB
+	preparing chan struct{}
+	blocked   chan struct{}
+}
+
+func (conn *blockingPrepareConn) Prepare(query string) (*sql.Stmt, error) {
+	return conn.PrepareContext(context.Background(), query)
+}
+
+func (conn *blockingPrepareConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if strings.Contains(query, "blocked") {
+		close(conn.preparing)
+		<-conn.blocked
+	}
+	return conn.DB.PrepareContext(ctx, query)
+}
+
+func TestPrepareStmtWithoutBlocking(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", filepath.Join(os.TempDir(), "gorm_prepare_stmt.db"))
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+
+	conn := &blockingPrepareConn{DB: sqlDB, preparing: make(chan struct{}), blocked: make(chan struct{})}
+	db, err := gorm.Open("sqlite3", conn)
+	if err != nil {
+		t.Fatalf("failed to open db, got %v", err)
+	}
+	defer db.Close()
+	db.SetPrepareStmt(true)
+
+	done := make(chan struct{})
+	go func() {
+		db.Exec("SELECT 'blocked'")
+		close(done)
+	}()
+	<-conn.preparing
+
+	finished := make(chan error)
+	go func() {
+		finished <- db.Exec("SELECT 'prepared'").Error
+	}()
+
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Errorf("no error should happen when preparing, but got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("preparing a statement should not block others")
+	}
+
+	close(conn.blocked)
+	<-done
+}
+
+func TestPrepareStmtClose(t *testing.T) {
+	db, _ := openPrepareStmtDB(t)
+	db.SetPrepareStmt(true)
+	db.Create(&PreparedUser{Name: "prepared"})
+
+	if err := db.Close(); err != nil {
+		t.Errorf("no error should happen when closing, but got %v", err)
+	}
+
+	if err := db.Create(&PreparedUser{Name: "prepared"}).Error; err == nil {
+		t.Errorf("should return error after closed")
+	}
+}
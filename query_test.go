@@ -1,8 +1,10 @@
 package gorm_test
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/jinzhu/gorm"
 
@@ -771,3 +773,178 @@ func TestPluckWithSelect(t *testing.T) {
 		t.Errorf("Should correctly pluck with select, got: %s", userAges)
 	}
 }
+
+func TestLocking(t *testing.T) {
+	DB.Save(&User{Name: "locking_user", Age: 20})
+
+	var users []User
+	sql := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.ForUpdate().Where("name = ?", "locking_user").Find(&users)
+	})
+
+	switch DB.Dialect().GetName() {
+	case "sqlite3":
+		if strings.Contains(sql, "FOR UPDATE") {
+			t.Errorf("Locking clause should be ignored by sqlite, but got %v", sql)
+		}
+	case "mssql":
+		if !strings.Contains(sql, "WITH (UPDLOCK, ROWLOCK)") {
+			t.Errorf("Locking should be rendered as table hints by mssql, but got %v", sql)
+		}
+	default:
+		if !strings.HasSuffix(sql, "FOR UPDATE") {
+			t.Errorf("Locking clause should be appended to the query, but got %v", sql)
+		}
+	}
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var user User
+		if err := tx.Clauses(gorm.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).Where("name = ?", "locking_user").First(&user).Error; err != nil {
+			return err
+		}
+
+		var count int
+		return tx.ForShare().Model(&User{}).Where("name = ?", "locking_user").Count(&count).Error
+	})
+	if err != nil {
+		t.Errorf("No error should happen when locking rows, but got %v", err)
+	}
+
+	if DB.Clauses("FOR UPDATE").Find(&users).Error == nil {
+		t.Errorf("Should got error with unsupported clauses")
+	}
+}
+
+type BatchUser struct {
+	gorm.Model
+	Name string
+	Age  int
+}
+
+func TestFindInBatches(t *testing.T) {
+	DB.DropTableIfExists(&BatchUser{})
+	DB.AutoMigrate(&BatchUser{})
+
+	for i := 1; i <= 7; i++ {
+		DB.Save(&BatchUser{Name: "find_in_batches", Age: i})
+	}
+	DB.Save(&BatchUser{Name: "other", Age: 8})
+	DB.Where("age = ?", 7).Delete(&BatchUser{})
+
+	var (
+		users   []BatchUser
+		batches []int
+		ages    []int
+	)
+	result := DB.Where("name = ?", "find_in_batches").Order("age desc").FindInBatches(&users, 2, func(tx *gorm.DB, batch int) error {
+		if int(tx.RowsAffected) != len(users) {
+			t.Errorf("rows affected should equal batch size, but got %v, %v", tx.RowsAffected, len(users))
+		}
+		batches = append(batches, batch)
+		for _, user := range users {
+			ages = append(ages, user.Age)
+		}
+		return nil
+	})
+
+	if result.Error != nil || result.RowsAffected != 6 {
+		t.Errorf("should find 6 records in batches, but got %v, %v", result.Error, result.RowsAffected)
+	}
+
+	if fmt.Sprint(batches) != "[1 2 3]" || fmt.Sprint(ages) != "[1 2 3 4 5 6]" {
+		t.Errorf("records should be found in batches ordered by primary key, but got %v, %v", batches, ages)
+	}
+
+	var count int
+	result = DB.Where("name = ?", "find_in_batches").FindInBatches(&users, 2, func(tx *gorm.DB, batch int) error {
+		count++
+		return errors.New("stop")
+	})
+
+	if result.Error == nil || result.Error.Error() != "stop" || count != 1 || result.RowsAffected != 2 {
+		t.Errorf("should stop when returning error, but got %v, %v", result.Error, count)
+	}
+}
+
+func TestFindInBatchesWithPreload(t *testing.T) {
+	DB.Save(&User{Name: "find_in_batches_preload", Emails: []Email{{Email: "batch1@example.com"}}})
+	DB.Save(&User{Name: "find_in_batches_preload", Emails: []Email{{Email: "batch2@example.com"}, {Email: "batch3@example.com"}}})
+
+	var (
+		users  []*User
+		emails int
+	)
+	DB.Preload("Emails").Where("name = ?", "find_in_batches_preload").FindInBatches(&users, 1, func(tx *gorm.DB, batch int) error {
+		emails += len(users[0].Emails)
+		return nil
+	})
+
+	if emails != 3 {
+		t.Errorf("should preload associations for each batch, but got %v emails", emails)
+	}
+}
+
+func TestFindInBatchesWithCompositePrimaryKey(t *testing.T) {
+	type BatchTranslation struct {
+		ID     uint   `gorm:"primary_key;auto_increment:false"`
+		Locale string `gorm:"primary_key"`
+	}
+
+	DB.DropTableIfExists(&BatchTranslation{})
+	DB.AutoMigrate(&BatchTranslation{})
+
+	for _, id := range []uint{1, 2} {
+		for _, locale := range []string{"de", "en", "fr"} {
+			DB.Save(&BatchTranslation{ID: id, Locale: locale})
+		}
+	}
+
+	var (
+		translations []BatchTranslation
+		found        []string
+	)
+	// batches end in the middle of translations sharing the same id
+	result := DB.FindInBatches(&translations, 2, func(tx *gorm.DB, batch int) error {
+		for _, translation := range translations {
+			found = append(found, fmt.Sprintf("%v-%v", translation.ID, translation.Locale))
+		}
+		return nil
+	})
+
+	if result.Error != nil || fmt.Sprint(found) != "[1-de 1-en 1-fr 2-de 2-en 2-fr]" {
+		t.Errorf("records should be found in batches ordered by all primary keys, but got %v, %v", found, result.Error)
+	}
+}
+
+func TestIter(t *testing.T) {
+	DB.DropTableIfExists(&BatchUser{})
+	DB.AutoMigrate(&BatchUser{})
+
+	for i := 1; i <= 3; i++ {
+		DB.Save(&BatchUser{Name: "iter", Age: i})
+	}
+	DB.Where("age = ?", 2).Delete(&BatchUser{})
+
+	iter, err := DB.Model(&BatchUser{}).Where("name = ?", "iter").Order("age").Iter()
+	if err != nil {
+		t.Fatalf("no error should happen when iterating, but got %v", err)
+	}
+	defer iter.Close()
+
+	var ages []int
+	for iter.Next() {
+		var user BatchUser
+		if err := iter.Scan(&user); err != nil {
+			t.Errorf("no error should happen when scanning, but got %v", err)
+		}
+		ages = append(ages, user.Age)
+	}
+
+	if iter.Err() != nil {
+		t.Errorf("no error should happen when iterating, but got %v", iter.Err())
+	}
+
+	if fmt.Sprint(ages) != "[1 3]" {
+		t.Errorf("should iterate records not deleted, but got %v", ages)
+	}
+}
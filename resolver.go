@@ -0,0 +1,181 @@
+package gorm
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// UsePrimary force reading from sources when a `Resolver` is used
+//     db.Clauses(gorm.UsePrimary).First(&user)
+var UsePrimary = usePrimaryClause{}
+
+type usePrimaryClause struct{}
+
+// ResolverPolicy choose a connection from sources or replicas
+type ResolverPolicy interface {
+	Resolve(connections []SQLCommon) SQLCommon
+}
+
+// ResolverPolicyFunc custom resolver policy
+type ResolverPolicyFunc func(connections []SQLCommon) SQLCommon
+
+// Resolve choose a connection with the func
+func (f ResolverPolicyFunc) Resolve(connections []SQLCommon) SQLCommon {
+	return f(connections)
+}
+
+// RandomPolicy choose a random connection, it is the default policy
+type RandomPolicy struct{}
+
+// Resolve choose a random connection
+func (RandomPolicy) Resolve(connections []SQLCommon) SQLCommon {
+	return connections[rand.Intn(len(connections))]
+}
+
+// RoundRobinPolicy return a policy choosing connections in turn
+func RoundRobinPolicy() ResolverPolicy {
+	return &roundRobinPolicy{}
+}
+
+type roundRobinPolicy struct {
+	count uint64
+}
+
+func (p *roundRobinPolicy) Resolve(connections []SQLCommon) SQLCommon {
+	return connections[(atomic.AddUint64(&p.count, 1)-1)%uint64(len(connections))]
+}
+
+// ResolverConfig connections of a resolver, the DB's own connection is used if there are no sources,
+// sources are used for reading if there are no replicas
+type ResolverConfig struct {
+	Sources  []SQLCommon
+	Replicas []SQLCommon
+	Policy   ResolverPolicy
+}
+
+// Resolver split reads and writes, queries are sent to replicas, creating, updating and deleting are sent to sources,
+// `Exec` and transactions use the DB's own connection
+//     db.Use(gorm.NewResolver(gorm.ResolverConfig{Replicas: []gorm.SQLCommon{replica1, replica2}}).
+//       Register(gorm.ResolverConfig{Sources: []gorm.SQLCommon{orderDB}}, &Order{}, "order_items"))
+type Resolver struct {
+	global  ResolverConfig
+	configs []resolverTables
+	tables  map[string]ResolverConfig
+	primary SQLCommon
+}
+
+type resolverTables struct {
+	config ResolverConfig
+	tables []interface{}
+}
+
+// NewResolver create a resolver with the config, it could be applied to given models or table names only
+func NewResolver(config ResolverConfig, tables ...interface{}) *Resolver {
+	return (&Resolver{}).Register(config, tables...)
+}
+
+// Register set the config of given models or table names, or the default config if there are no tables
+func (r *Resolver) Register(config ResolverConfig, tables ...interface{}) *Resolver {
+	if config.Policy == nil {
+		config.Policy = RandomPolicy{}
+	}
+
+	if len(tables) == 0 {
+		r.global = config
+	} else {
+		r.configs = append(r.configs, resolverTables{config: config, tables: tables})
+	}
+	return r
+}
+
+// Initialize register the resolver's callbacks to the db, used by `DB.Use`
+func (r *Resolver) Initialize(db *DB) error {
+	r.primary = db.db
+	r.tables = map[string]ResolverConfig{}
+	for _, configTables := range r.configs {
+		for _, table := range configTables.tables {
+			if tableName, ok := table.(string); ok {
+				r.tables[tableName] = configTables.config
+			} else {
+				r.tables[db.NewScope(table).TableName()] = configTables.config
+			}
+		}
+	}
+
+	db.Callback().Query().Before("gorm:query").Register("gorm:resolver", r.useReplica)
+	db.Callback().Query().After("gorm:after_query").Register("gorm:resolver_restore", r.restore)
+	db.Callback().RowQuery().Before("gorm:row_query").Register("gorm:resolver", r.useReplica)
+	db.Callback().RowQuery().After("gorm:row_query").Register("gorm:resolver_restore", r.restore)
+	db.Callback().Create().Before("gorm:begin_transaction").Register("gorm:resolver", r.useSource)
+	db.Callback().Create().After("gorm:commit_or_rollback_transaction").Register("gorm:resolver_restore", r.restore)
+	db.Callback().Update().Before("gorm:begin_transaction").Register("gorm:resolver", r.useSource)
+	db.Callback().Update().After("gorm:commit_or_rollback_transaction").Register("gorm:resolver_restore", r.restore)
+	db.Callback().Delete().Before("gorm:begin_transaction").Register("gorm:resolver", r.useSource)
+	db.Callback().Delete().After("gorm:commit_or_rollback_transaction").Register("gorm:resolver_restore", r.restore)
+	return nil
+}
+
+func (r *Resolver) config(scope *Scope) ResolverConfig {
+	if config, ok := r.tables[scope.TableName()]; ok {
+		return config
+	}
+	return r.global
+}
+
+// resolved check if the connection is one of replicas or sources, e.g. used by dbs created in callbacks of queries
+func (r *Resolver) resolved(conn SQLCommon) bool {
+	configs := []ResolverConfig{r.global}
+	for _, config := range r.tables {
+		configs = append(configs, config)
+	}
+
+	for _, config := range configs {
+		for _, connections := range [][]SQLCommon{config.Sources, config.Replicas} {
+			for _, connection := range connections {
+				if connection == conn {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// use switch the connection of current operation, the original one is restored by restore
+func (r *Resolver) use(scope *Scope, conn SQLCommon) {
+	if _, ok := scope.InstanceGet("gorm:resolver_connection"); !ok {
+		scope.InstanceSet("gorm:resolver_connection", scope.db.db)
+	}
+	scope.db.db = conn
+}
+
+func (r *Resolver) useReplica(scope *Scope) {
+	// locking reads like `FOR UPDATE` can't be served by read-only replicas
+	if scope.Search.usePrimary || scope.Search.locking != nil {
+		r.useSource(scope)
+	} else if _, ok := scope.db.db.(sqlDb); ok {
+		if config := r.config(scope); len(config.Replicas) > 0 {
+			r.use(scope, config.Policy.Resolve(config.Replicas))
+		} else if len(config.Sources) > 0 {
+			r.use(scope, config.Policy.Resolve(config.Sources))
+		}
+	}
+}
+
+func (r *Resolver) useSource(scope *Scope) {
+	// keep using the connection of current transaction
+	if _, ok := scope.db.db.(sqlDb); ok {
+		if config := r.config(scope); len(config.Sources) > 0 {
+			r.use(scope, config.Policy.Resolve(config.Sources))
+		} else if r.resolved(scope.db.db) {
+			r.use(scope, r.primary)
+		}
+	}
+}
+
+// restore restore the connection switched by the resolver, so it won't be kept by the returned db
+func (r *Resolver) restore(scope *Scope) {
+	if conn, ok := scope.InstanceGet("gorm:resolver_connection"); ok {
+		scope.db.db = conn.(SQLCommon)
+	}
+}
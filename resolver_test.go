@@ -0,0 +1,151 @@
+package gorm_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+type ResolverUser struct {
+	ID   uint
+	Name string
+}
+
+type ResolverOrder struct {
+	ID   uint
+	Name string
+}
+
+type ResolverVisit struct {
+	ID   uint
+	Name string
+}
+
+// AfterFind write with the db of the query, which is sent to replicas
+func (visit *ResolverVisit) AfterFind(tx *gorm.DB) error {
+	return tx.Create(&ResolverUser{Name: "visited"}).Error
+}
+
+func openResolverDB(t *testing.T, name string) *gorm.DB {
+	path := filepath.Join(os.TempDir(), "gorm_resolver_"+name+".db")
+	os.Remove(path)
+
+	db, err := gorm.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open %v, got %v", path, err)
+	}
+
+	db.AutoMigrate(&ResolverUser{}, &ResolverOrder{}, &ResolverVisit{})
+	db.Create(&ResolverUser{Name: name})
+	db.Create(&ResolverOrder{Name: name})
+	db.Create(&ResolverVisit{Name: name})
+	return db
+}
+
+func resolvedName(db *gorm.DB) string {
+	var user ResolverUser
+	db.First(&user)
+	return user.Name
+}
+
+func TestResolver(t *testing.T) {
+	var (
+		primary  = openResolverDB(t, "primary")
+		replica1 = openResolverDB(t, "replica1")
+		replica2 = openResolverDB(t, "replica2")
+		orders   = openResolverDB(t, "orders")
+	)
+	defer primary.Close()
+	defer replica1.Close()
+	defer replica2.Close()
+	defer orders.Close()
+
+	err := primary.Use(gorm.NewResolver(gorm.ResolverConfig{
+		Replicas: []gorm.SQLCommon{replica1.DB(), replica2.DB()},
+		Policy:   gorm.RoundRobinPolicy(),
+	}).Register(gorm.ResolverConfig{Sources: []gorm.SQLCommon{orders.DB()}}, &ResolverOrder{}))
+	if err != nil {
+		t.Fatalf("failed to use resolver, got %v", err)
+	}
+
+	if name1, name2, name3 := resolvedName(primary), resolvedName(primary), resolvedName(primary); name1 != "replica1" || name2 != "replica2" || name3 != "replica1" {
+		t.Errorf("queries should be sent to replicas in turn, but got %v, %v, %v", name1, name2, name3)
+	}
+
+	var name string
+	primary.Model(&ResolverUser{}).Select("name").Row().Scan(&name)
+	if name != "replica2" {
+		t.Errorf("row queries should be sent to replicas, but got %v", name)
+	}
+
+	if name := resolvedName(primary.Clauses(gorm.UsePrimary)); name != "primary" {
+		t.Errorf("queries should be sent to primary with UsePrimary, but got %v", name)
+	}
+
+	if name1, name2 := resolvedName(primary.ForUpdate()), resolvedName(primary.Clauses(gorm.Locking{Strength: "SHARE"})); name1 != "primary" || name2 != "primary" {
+		t.Errorf("locking queries should be sent to primary, but got %v, %v", name1, name2)
+	}
+
+	primary.Create(&ResolverUser{Name: "created"})
+	primary.Exec("UPDATE resolver_users SET name = ? WHERE name = ?", "executed", "created")
+	var count int
+	primary.Clauses(gorm.UsePrimary).Model(&ResolverUser{}).Where("name = ?", "executed").Count(&count)
+	if count != 1 {
+		t.Errorf("creating and executing should be sent to primary, but got %v records", count)
+	}
+
+	var users []ResolverUser
+	queried := primary.Find(&users)
+	queried.Create(&ResolverUser{Name: "created after querying"})
+	if primary.Clauses(gorm.UsePrimary).Model(&ResolverUser{}).Where("name = ?", "created after querying").Count(&count); count != 1 {
+		t.Errorf("replicas should not be kept by the db returned by queries, but got %v records in primary", count)
+	}
+
+	primary.First(&ResolverVisit{})
+	if primary.Clauses(gorm.UsePrimary).Model(&ResolverUser{}).Where("name = ?", "visited").Count(&count); count != 1 {
+		t.Errorf("creating in callbacks of queries should be sent to primary, but got %v records", count)
+	}
+
+	tx := primary.Begin()
+	if name := resolvedName(tx); name != "primary" {
+		t.Errorf("queries in transactions should be sent to primary, but got %v", name)
+	}
+	tx.Rollback()
+
+	order := ResolverOrder{Name: "order"}
+	primary.Create(&order)
+	primary.Model(&order).Update("name", "updated order")
+
+	var result ResolverOrder
+	if primary.Where("name = ?", "updated order").First(&result).Error != nil {
+		t.Errorf("orders should be read from its own sources")
+	}
+
+	if orders.Where("name = ?", "updated order").First(&ResolverOrder{}).RecordNotFound() {
+		t.Errorf("orders should be written to its own sources")
+	}
+}
+
+func TestResolverWithCustomPolicy(t *testing.T) {
+	var (
+		primary  = openResolverDB(t, "primary")
+		replica1 = openResolverDB(t, "replica1")
+		replica2 = openResolverDB(t, "replica2")
+	)
+	defer primary.Close()
+	defer replica1.Close()
+	defer replica2.Close()
+
+	primary.Use(gorm.NewResolver(gorm.ResolverConfig{
+		Replicas: []gorm.SQLCommon{replica1.DB(), replica2.DB()},
+		Policy: gorm.ResolverPolicyFunc(func(connections []gorm.SQLCommon) gorm.SQLCommon {
+			return connections[len(connections)-1]
+		}),
+	}))
+
+	if name1, name2 := resolvedName(primary), resolvedName(primary); name1 != "replica2" || name2 != "replica2" {
+		t.Errorf("queries should be sent to replicas chosen by custom policy, but got %v, %v", name1, name2)
+	}
+}
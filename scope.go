@@ -2,12 +2,15 @@ package gorm
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -19,6 +22,7 @@ type Scope struct {
 	SQL             string
 	SQLVars         []interface{}
 	db              *DB
+	redactedVars    map[int]bool
 	instanceID      string
 	primaryKeyField *Field
 	skipLeft        bool
@@ -61,9 +65,14 @@ func (scope *Scope) SQLDB() SQLCommon {
 	return scope.db.db
 }
 
+// Context return the context of current operation, refer `DB.WithContext`
+func (scope *Scope) Context() context.Context {
+	return scope.db.Context()
+}
+
 // Dialect get dialect
 func (scope *Scope) Dialect() Dialect {
-	return scope.db.parent.dialect
+	return scope.db.Dialect()
 }
 
 // Quote used to quote string to escape them for database
@@ -134,14 +143,18 @@ func (scope *Scope) Fields() []*Field {
 // FieldByName find `gorm.Field` with field name or db name
 func (scope *Scope) FieldByName(name string) (field *Field, ok bool) {
 	var (
-		dbName           = ToDBName(name)
+		fields           = scope.Fields()
 		mostMatchedField *Field
 	)
 
-	for _, field := range scope.Fields() {
+	for _, field := range fields {
 		if field.Name == name || field.DBName == name {
 			return field, true
 		}
+	}
+
+	dbName := scope.db.NamingStrategy().ColumnName(scope.TableName(), name)
+	for _, field := range fields {
 		if field.DBName == dbName {
 			mostMatchedField = field
 		}
@@ -217,7 +230,7 @@ func (scope *Scope) SetColumn(column interface{}, value interface{}) error {
 		return field.Set(value)
 	} else if name, ok := column.(string); ok {
 		var (
-			dbName           = ToDBName(name)
+			dbName           = scope.db.NamingStrategy().ColumnName(scope.TableName(), name)
 			mostMatchedField *Field
 		)
 		for _, field := range scope.Fields() {
@@ -277,6 +290,66 @@ func (scope *Scope) AddToVars(value interface{}) string {
 	return scope.Dialect().BindVar(len(scope.SQLVars))
 }
 
+// addColumnToVars add value of column to vars like AddToVars, the value will be redacted in logs if the column should be redacted
+func (scope *Scope) addColumnToVars(column string, value interface{}) string {
+	if _, ok := value.(*expr); !ok {
+		if field, ok := scope.FieldByName(column); ok {
+			serializer, err := field.serializer()
+			if serializer != nil {
+				value, err = serializer.Value(field, value)
+			}
+			scope.Err(err)
+		}
+	}
+
+	placeholder := scope.AddToVars(value)
+	if _, ok := value.(*expr); !ok && scope.isRedactedColumn(column) {
+		scope.markRedactedVars(len(scope.SQLVars) - 1)
+	}
+	return placeholder
+}
+
+// markRedactedVars mark vars added since the index to be redacted in logs
+func (scope *Scope) markRedactedVars(from int) {
+	if scope.redactedVars == nil {
+		scope.redactedVars = map[int]bool{}
+	}
+	for idx := from; idx < len(scope.SQLVars); idx++ {
+		scope.redactedVars[idx] = true
+	}
+}
+
+// conditionColumns return columns compared with each placeholder of the condition like `password = ?`, blank if unknown
+func conditionColumns(condition string) (columns []string) {
+	for idx, s := range condition {
+		if s == '?' {
+			var column string
+			if matches := conditionColumnRegexp.FindStringSubmatch(condition[:idx]); len(matches) > 1 {
+				column = matches[1]
+			}
+			columns = append(columns, column)
+		}
+	}
+	return
+}
+
+// isRedactedColumn check if column's values should be redacted in logs, which is tagged with `redact` or included in RedactedColumns
+func (scope *Scope) isRedactedColumn(column string) bool {
+	if field, ok := scope.FieldByName(column); ok {
+		if _, ok := field.TagSettings["REDACT"]; ok {
+			return true
+		}
+		column = field.DBName
+	}
+
+	for _, redactedColumn := range RedactedColumns {
+		if strings.EqualFold(redactedColumn, column) {
+			return true
+		}
+	}
+	return false
+}
+
 // SelectAttrs return selected attributes
 func (scope *Scope) SelectAttrs() []string {
 	if scope.selectAttrs == nil {
@@ -360,8 +433,8 @@ func (scope *Scope) Raw(sql string) *Scope {
 func (scope *Scope) Exec() *Scope {
 	defer scope.trace(NowFunc())
 
-	if !scope.HasError() {
-		if result, err := scope.SQLDB().Exec(scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
+	if !scope.HasError() && !scope.dryRun() {
+		if result, err := execContext(scope.Context(), scope.SQLDB(), scope.SQL, scope.SQLVars...); scope.Err(err) == nil {
 			if count, err := result.RowsAffected(); scope.Err(err) == nil {
 				scope.db.RowsAffected = count
 			}
@@ -399,20 +472,36 @@ func (scope *Scope) InstanceGet(name string) (interface{}, bool) {
 	return scope.Get(name + scope.InstanceID())
 }
 
-// Begin start a transaction
+// Begin start a transaction, if current operation is already in a transaction, will create a savepoint instead
 func (scope *Scope) Begin() *Scope {
-	if db, ok := scope.SQLDB().(sqlDb); ok {
-		if tx, err := db.Begin(); err == nil {
-			scope.db.db = interface{}(tx).(SQLCommon)
+	if scope.dryRun() {
+		return scope
+	}
+
+	if _, ok := scope.SQLDB().(sqlTx); ok {
+		savePoint := fmt.Sprintf("sp%p", scope)
+		if scope.db.execSavePoint(savePointSQL(scope.Dialect(), savePoint)) == nil {
+			scope.InstanceSet("gorm:started_savepoint", savePoint)
+		}
+	} else if _, ok := scope.SQLDB().(sqlDb); ok {
+		if tx, err := beginTx(scope.Context(), scope.SQLDB(), nil); err == nil {
+			scope.db.db = tx
 			scope.InstanceSet("gorm:started_transaction", true)
 		}
 	}
 	return scope
 }
 
-// CommitOrRollback commit current transaction if no error happened, otherwise will rollback it
+// CommitOrRollback commit current transaction if no error happened, otherwise will rollback it.
+// If a savepoint was created by `Begin`, will rollback to the savepoint when error happened or release it otherwise, and leave the transaction to its owner
 func (scope *Scope) CommitOrRollback() *Scope {
-	if _, ok := scope.InstanceGet("gorm:started_transaction"); ok {
+	if savePoint, ok := scope.InstanceGet("gorm:started_savepoint"); ok {
+		if scope.HasError() {
+			scope.db.execSavePoint(rollbackToSavePointSQL(scope.Dialect(), savePoint.(string)))
+		} else {
+			scope.Err(scope.db.releaseSavePoint(savePoint.(string)))
+		}
+	} else if _, ok := scope.InstanceGet("gorm:started_transaction"); ok {
 		if db, ok := scope.db.db.(sqlTx); ok {
 			if scope.HasError() {
 				db.Rollback()
@@ -466,6 +555,9 @@ var (
 	countingQueryRegexp = regexp.MustCompile("(?i)^count(.+)$")
 )
 
+// conditionColumnRegexp match the column compared with the following placeholder, like `password = `, `"users"."password" IN (`
+var conditionColumnRegexp = regexp.MustCompile("(?i)(\\w+)[`\"\\]]?\\s*(=|<>|!=|(>|<)(=?)|\\s(NOT\\s+)?(LIKE|IN))\\s*\\(?\\s*$")
+
 func (scope *Scope) quoteIfPossible(str string) string {
 	if columnRegexp.MatchString(str) {
 		return scope.Quote(str)
@@ -480,6 +572,7 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 		selectFields       []*Field
 		selectedColumnsMap = map[string]int{}
 		resetFields        = map[int]*Field{}
+		serializedFields   = map[int]*Field{}
 	)
 
 	for index, column := range columns {
@@ -491,8 +584,12 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 		}
 
 		for fieldIndex, field := range selectFields {
-			if field.DBName == column {
-				if field.Field.Kind() == reflect.Ptr {
+			if field.DBName == column && field.readable() {
+				if _, ok := field.TagSettings["SERIALIZER"]; ok {
+					var dbValue interface{}
+					values[index] = &dbValue
+					serializedFields[index] = field
+				} else if field.Field.Kind() == reflect.Ptr {
 					values[index] = field.Field.Addr().Interface()
 				} else {
 					reflectValue := reflect.New(reflect.PtrTo(field.Struct.Type))
@@ -510,13 +607,23 @@ func (scope *Scope) scan(rows *sql.Rows, columns []string, fields []*Field) {
 		}
 	}
 
-	scope.Err(rows.Scan(values...))
+	scanErr := scope.Err(rows.Scan(values...))
 
 	for index, field := range resetFields {
 		if v := reflect.ValueOf(values[index]).Elem().Elem(); v.IsValid() {
 			field.Field.Set(v)
 		}
 	}
+
+	if scanErr == nil {
+		for index, field := range serializedFields {
+			serializer, err := field.serializer()
+			if serializer != nil {
+				err = serializer.Scan(field, *values[index].(*interface{}))
+			}
+			scope.Err(err)
+		}
+	}
 }
 
 func (scope *Scope) primaryCondition(value interface{}) string {
@@ -568,7 +675,7 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 		var sqls []string
 		for key, value := range value {
 			if value != nil {
-				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", quotedTableName, scope.Quote(key), equalSQL, scope.AddToVars(value)))
+				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", quotedTableName, scope.Quote(key), equalSQL, scope.addColumnToVars(key, value)))
 			} else {
 				if !include {
 					sqls = append(sqls, fmt.Sprintf("(%v.%v IS NOT NULL)", quotedTableName, scope.Quote(key)))
@@ -589,7 +696,11 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 
 		for _, field := range newScope.Fields() {
 			if !field.IsIgnored && !field.IsBlank {
-				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", quotedTableName, scope.Quote(field.DBName), equalSQL, scope.AddToVars(field.Field.Interface())))
+				sqls = append(sqls, fmt.Sprintf("(%v.%v %s %v)", quotedTableName, scope.Quote(field.DBName), equalSQL, scope.addColumnToVars(field.DBName, field.Field.Interface())))
+				// the condition's model might be different from the scope's
+				if _, ok := field.TagSettings["REDACT"]; ok {
+					scope.markRedactedVars(len(scope.SQLVars) - 1)
+				}
 			}
 		}
 		return strings.Join(sqls, " AND ")
@@ -600,8 +711,10 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 
 	replacements := []string{}
 	args := clause["args"].([]interface{})
-	for _, arg := range args {
+	columns := conditionColumns(str)
+	for idx, arg := range args {
 		var err error
+		varsCount := len(scope.SQLVars)
 		switch reflect.ValueOf(arg).Kind() {
 		case reflect.Slice: // For where("id in (?)", []int64{1,2})
 			if scanner, ok := interface{}(arg).(driver.Valuer); ok {
@@ -642,6 +755,10 @@ func (scope *Scope) buildCondition(clause map[string]interface{}, include bool)
 			replacements = append(replacements, scope.AddToVars(arg))
 		}
 
+		if idx < len(columns) && columns[idx] != "" && scope.isRedactedColumn(columns[idx]) {
+			scope.markRedactedVars(varsCount)
+		}
+
 		if err != nil {
 			scope.Err(err)
 		}
@@ -709,13 +826,12 @@ func (scope *Scope) buildSelectQuery(clause map[string]interface{}) (str string)
 func (scope *Scope) whereSQL() (sql string) {
 	var (
 		quotedTableName                                = scope.QuotedTableName()
-		deletedAtField, hasDeletedAtField              = scope.FieldByName("DeletedAt")
+		softDeleteField, softDeleter                   = scope.softDeleteField()
 		primaryConditions, andConditions, orConditions []string
 	)
 
-	if !scope.Search.Unscoped && hasDeletedAtField {
-		sql := fmt.Sprintf("%v.%v IS NULL", quotedTableName, scope.Quote(deletedAtField.DBName))
-		primaryConditions = append(primaryConditions, sql)
+	if softDeleteField != nil && (scope.Search.OnlyDeleted || !scope.Search.Unscoped) {
+		primaryConditions = append(primaryConditions, scope.softDeleteSQL(softDeleteField, softDeleter, scope.Search.OnlyDeleted))
 	}
 
 	if !scope.PrimaryKeyZero() {
@@ -840,7 +956,19 @@ func (scope *Scope) prepareQuerySQL() {
 	if scope.Search.raw {
 		scope.Raw(scope.CombinedConditionSql())
 	} else {
-		scope.Raw(fmt.Sprintf("SELECT %v FROM %v %v", scope.selectSQL(), scope.QuotedTableName(), scope.CombinedConditionSql()))
+		var lockingClause, tableHint string
+		if scope.Search.locking != nil {
+			lockingClause, tableHint = lockingSQL(scope.Dialect(), *scope.Search.locking)
+		}
+
+		scope.Raw(fmt.Sprintf(
+			"SELECT %v FROM %v%v %v%v",
+			scope.selectSQL(),
+			scope.QuotedTableName(),
+			addExtraSpaceIfExist(tableHint),
+			scope.CombinedConditionSql(),
+			addExtraSpaceIfExist(lockingClause),
+		))
 	}
 	return
 }
@@ -862,7 +990,7 @@ func (scope *Scope) callCallbacks(funcs []*func(s *Scope)) *Scope {
 	return scope
 }
 
-func convertInterfaceToMap(values interface{}, withIgnoredField bool) map[string]interface{} {
+func convertInterfaceToMap(values interface{}, withIgnoredField bool, db *DB) map[string]interface{} {
 	var attrs = map[string]interface{}{}
 
 	switch value := values.(type) {
@@ -870,7 +998,7 @@ func convertInterfaceToMap(values interface{}, withIgnoredField bool) map[string
 		return value
 	case []interface{}:
 		for _, v := range value {
-			for key, value := range convertInterfaceToMap(v, withIgnoredField) {
+			for key, value := range convertInterfaceToMap(v, withIgnoredField, db) {
 				attrs[key] = value
 			}
 		}
@@ -880,10 +1008,10 @@ func convertInterfaceToMap(values interface{}, withIgnoredField bool) map[string
 		switch reflectValue.Kind() {
 		case reflect.Map:
 			for _, key := range reflectValue.MapKeys() {
-				attrs[ToDBName(key.Interface().(string))] = reflectValue.MapIndex(key).Interface()
+				attrs[db.NamingStrategy().ColumnName("", key.Interface().(string))] = reflectValue.MapIndex(key).Interface()
 			}
 		default:
-			for _, field := range (&Scope{Value: values}).Fields() {
+			for _, field := range (&Scope{db: db, Value: values}).Fields() {
 				if !field.IsBlank && (withIgnoredField || !field.IsIgnored) {
 					attrs[field.DBName] = field.Field.Interface()
 				}
@@ -895,12 +1023,12 @@ func convertInterfaceToMap(values interface{}, withIgnoredField bool) map[string
 
 func (scope *Scope) updatedAttrsWithValues(value interface{}) (results map[string]interface{}, hasUpdate bool) {
 	if scope.IndirectValue().Kind() != reflect.Struct {
-		return convertInterfaceToMap(value, false), true
+		return convertInterfaceToMap(value, false, scope.db), true
 	}
 
 	results = map[string]interface{}{}
 
-	for key, value := range convertInterfaceToMap(value, true) {
+	for key, value := range convertInterfaceToMap(value, true, scope.db) {
 		if field, ok := scope.FieldByName(key); ok && scope.changeableField(field) {
 			if _, ok := value.(*expr); ok {
 				hasUpdate = true
@@ -979,7 +1107,7 @@ func (scope *Scope) pluck(column string, value interface{}) *Scope {
 	}
 
 	rows, err := scope.rows()
-	if scope.Err(err) == nil {
+	if scope.Err(err) == nil && rows != nil {
 		defer rows.Close()
 		for rows.Next() {
 			elem := reflect.New(dest.Type().Elem()).Interface()
@@ -1004,14 +1132,17 @@ func (scope *Scope) count(value interface{}) *Scope {
 		}
 	}
 	scope.Search.ignoreOrderQuery = true
-	scope.Err(scope.row().Scan(value))
+	scope.Search.locking = nil
+	if row := scope.row(); row != nil {
+		scope.Err(row.Scan(value))
+	}
 	return scope
 }
 
 func (scope *Scope) typeName() string {
 	typ := scope.IndirectValue().Type()
 
-	for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Ptr {
+	for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
 	}
 
@@ -1021,11 +1152,23 @@ func (scope *Scope) typeName() string {
 // trace print sql log
 func (scope *Scope) trace(t time.Time) {
 	if len(scope.SQL) > 0 {
-		scope.db.slog(scope.SQL, t, scope.SQLVars...)
+		scope.db.SQL, scope.db.SQLVars, scope.db.redactedVars = scope.SQL, scope.SQLVars, scope.redactedVars
+
+		scope.db.trace(t, scope.SQL, redactVars(scope.SQLVars, scope.redactedVars), scope.db.Error)
 	}
 }
 
+// dryRun return true if statements should only be built without executing, refer `DB.DryRun`
+func (scope *Scope) dryRun() bool {
+	dryRun, _ := scope.Get("gorm:dry_run")
+	return dryRun == true
+}
+
 func (scope *Scope) changeableField(field *Field) bool {
+	if !field.writable("create") && !field.writable("update") {
+		return false
+	}
+
 	if selectAttrs := scope.SelectAttrs(); len(selectAttrs) > 0 {
 		for _, attr := range selectAttrs {
 			if field.Name == attr || field.DBName == attr {
@@ -1106,43 +1249,77 @@ func (scope *Scope) createJoinTable(field *StructField) {
 		joinTableHandler := relationship.JoinTableHandler
 		joinTable := joinTableHandler.Table(scope.db)
 		if !scope.Dialect().HasTable(joinTable) {
-			toScope := &Scope{Value: reflect.New(field.Struct.Type).Interface()}
-
-			var sqlTypes, primaryKeys []string
-			for idx, fieldName := range relationship.ForeignFieldNames {
-				if field, ok := scope.FieldByName(fieldName); ok {
-					foreignKeyStruct := field.clone()
-					foreignKeyStruct.IsPrimaryKey = false
-					foreignKeyStruct.TagSettings["IS_JOINTABLE_FOREIGNKEY"] = "true"
-					delete(foreignKeyStruct.TagSettings, "AUTO_INCREMENT")
-					sqlTypes = append(sqlTypes, scope.Quote(relationship.ForeignDBNames[idx])+" "+scope.Dialect().DataTypeOf(foreignKeyStruct))
-					primaryKeys = append(primaryKeys, scope.Quote(relationship.ForeignDBNames[idx]))
-				}
+			if scope.Err(scope.NewDB().Exec(scope.createJoinTableSQL(field)).Error) == nil {
+				scope.tableCreated(joinTable)
 			}
+		}
+		scope.NewDB().Table(joinTable).AutoMigrate(joinTableHandler)
+	}
+}
 
-			for idx, fieldName := range relationship.AssociationForeignFieldNames {
-				if field, ok := toScope.FieldByName(fieldName); ok {
-					foreignKeyStruct := field.clone()
-					foreignKeyStruct.IsPrimaryKey = false
-					foreignKeyStruct.TagSettings["IS_JOINTABLE_FOREIGNKEY"] = "true"
-					delete(foreignKeyStruct.TagSettings, "AUTO_INCREMENT")
-					sqlTypes = append(sqlTypes, scope.Quote(relationship.AssociationForeignDBNames[idx])+" "+scope.Dialect().DataTypeOf(foreignKeyStruct))
-					primaryKeys = append(primaryKeys, scope.Quote(relationship.AssociationForeignDBNames[idx]))
-				}
-			}
+// createJoinTableSQL return the statement creating the join table of the many2many field
+func (scope *Scope) createJoinTableSQL(field *StructField) string {
+	var (
+		relationship            = field.Relationship
+		toScope                 = &Scope{db: scope.db, Value: reflect.New(field.Struct.Type).Interface()}
+		sqlTypes, primaryKeys []string
+	)
 
-			scope.Err(scope.NewDB().Exec(fmt.Sprintf("CREATE TABLE %v (%v, PRIMARY KEY (%v))%s", scope.Quote(joinTable), strings.Join(sqlTypes, ","), strings.Join(primaryKeys, ","), scope.getTableOptions())).Error)
+	for idx, fieldName := range relationship.ForeignFieldNames {
+		if field, ok := scope.FieldByName(fieldName); ok {
+			foreignKeyStruct := field.clone()
+			foreignKeyStruct.IsPrimaryKey = false
+			foreignKeyStruct.TagSettings["IS_JOINTABLE_FOREIGNKEY"] = "true"
+			delete(foreignKeyStruct.TagSettings, "AUTO_INCREMENT")
+			sqlTypes = append(sqlTypes, scope.Quote(relationship.ForeignDBNames[idx])+" "+scope.Dialect().DataTypeOf(foreignKeyStruct))
+			primaryKeys = append(primaryKeys, scope.Quote(relationship.ForeignDBNames[idx]))
+		}
+	}
+
+	for idx, fieldName := range relationship.AssociationForeignFieldNames {
+		if field, ok := toScope.FieldByName(fieldName); ok {
+			foreignKeyStruct := field.clone()
+			foreignKeyStruct.IsPrimaryKey = false
+			foreignKeyStruct.TagSettings["IS_JOINTABLE_FOREIGNKEY"] = "true"
+			delete(foreignKeyStruct.TagSettings, "AUTO_INCREMENT")
+			sqlTypes = append(sqlTypes, scope.Quote(relationship.AssociationForeignDBNames[idx])+" "+scope.Dialect().DataTypeOf(foreignKeyStruct))
+			primaryKeys = append(primaryKeys, scope.Quote(relationship.AssociationForeignDBNames[idx]))
 		}
-		scope.NewDB().Table(joinTable).AutoMigrate(joinTableHandler)
 	}
+
+	var (
+		joinTable   = relationship.JoinTableHandler.Table(scope.db)
+		constraints string
+	)
+
+	if foreignKeys := scope.inlineForeignKeys(joinTable, scope.relationshipConstraints(field)); len(foreignKeys) > 0 {
+		constraints = ", " + strings.Join(foreignKeys, ", ")
+	}
+
+	return fmt.Sprintf("CREATE TABLE %v (%v, PRIMARY KEY (%v)%v)%s", scope.Quote(joinTable), strings.Join(sqlTypes, ","), strings.Join(primaryKeys, ","), constraints, scope.getTableOptions())
 }
 
 func (scope *Scope) createTable() *Scope {
+	if !scope.Raw(scope.createTableSQL()).Exec().HasError() {
+		scope.tableCreated(scope.TableName())
+	}
+
+	// join tables reference the table, create them after it
+	for _, field := range scope.GetModelStruct().StructFields {
+		scope.createJoinTable(field)
+	}
+
+	scope.autoIndex()
+	return scope
+}
+
+// createTableSQL return the statement creating the table of the model, without join tables and indexes
+func (scope *Scope) createTableSQL() string {
 	var tags []string
 	var primaryKeys []string
 	var primaryKeyInColumnType = false
 	for _, field := range scope.GetModelStruct().StructFields {
-		if field.IsNormal {
+		if field.IsNormal && !field.ignoreMigration() {
 			sqlTag := scope.Dialect().DataTypeOf(field)
 
 			// Check if the primary key constraint was specified as
@@ -1158,7 +1335,6 @@ func (scope *Scope) createTable() *Scope {
 		if field.IsPrimaryKey {
 			primaryKeys = append(primaryKeys, scope.Quote(field.DBName))
 		}
-		scope.createJoinTable(field)
 	}
 
 	var primaryKeyStr string
@@ -1166,10 +1342,11 @@ func (scope *Scope) createTable() *Scope {
 		primaryKeyStr = fmt.Sprintf(", PRIMARY KEY (%v)", strings.Join(primaryKeys, ","))
 	}
 
-	scope.Raw(fmt.Sprintf("CREATE TABLE %v (%v %v)%s", scope.QuotedTableName(), strings.Join(tags, ","), primaryKeyStr, scope.getTableOptions())).Exec()
+	if foreignKeys := scope.inlineForeignKeys(scope.TableName(), scope.foreignKeyConstraints()); len(foreignKeys) > 0 {
+		primaryKeyStr += ", " + strings.Join(foreignKeys, ", ")
+	}
 
-	scope.autoIndex()
-	return scope
+	return fmt.Sprintf("CREATE TABLE %v (%v %v)%s", scope.QuotedTableName(), strings.Join(tags, ","), primaryKeyStr, scope.getTableOptions())
 }
 
 func (scope *Scope) dropTable() *Scope {
@@ -1185,37 +1362,126 @@ func (scope *Scope) dropColumn(column string) {
 	scope.Raw(fmt.Sprintf("ALTER TABLE %v DROP COLUMN %v", scope.QuotedTableName(), scope.Quote(column))).Exec()
 }
 
+func (scope *Scope) alterColumn(name string) {
+	field, ok := scope.FieldByName(name)
+	if !ok {
+		scope.Err(fmt.Errorf("field %v not found", name))
+		return
+	}
+
+	if dialect, ok := scope.alterTableDialect(); ok {
+		scope.Err(dialect.AlterColumn(scope.TableName(), field.StructField))
+	}
+}
+
+// alterTableDialect return the dialect altering tables, ErrNotSupported is added if the dialect doesn't implement AlterTableDialect
+func (scope *Scope) alterTableDialect() (AlterTableDialect, bool) {
+	dialect, ok := scope.Dialect().(AlterTableDialect)
+	if !ok {
+		scope.Err(ErrNotSupported)
+	}
+	return dialect, ok
+}
+
+func (scope *Scope) renameColumn(oldName string, newName string) {
+	if dialect, ok := scope.alterTableDialect(); ok {
+		scope.Err(dialect.RenameColumn(scope.TableName(), scope.columnNameOf(oldName), scope.columnNameOf(newName)))
+	}
+}
+
+// columnNameOf return the column name of the field, or name converted by the naming strategy if the model hasn't the field
+func (scope *Scope) columnNameOf(name string) string {
+	if field, ok := scope.FieldByName(name); ok {
+		return field.DBName
+	}
+	return scope.db.NamingStrategy().ColumnName(scope.TableName(), name)
+}
+
+func (scope *Scope) renameTable(oldName string, newName string) {
+	if dialect, ok := scope.alterTableDialect(); ok {
+		scope.Err(dialect.RenameTable(oldName, newName))
+	}
+}
+
+func (scope *Scope) renameIndex(oldName string, newName string) {
+	if dialect, ok := scope.alterTableDialect(); ok {
+		scope.Err(dialect.RenameIndex(scope.TableName(), oldName, newName))
+	}
+}
+
+func (scope *Scope) dropConstraint(constraintName string) {
+	if dialect, ok := scope.alterTableDialect(); ok {
+		scope.Err(dialect.DropConstraint(scope.TableName(), constraintName))
+	}
+}
+
 func (scope *Scope) addIndex(unique bool, indexName string, column ...string) {
-	if scope.Dialect().HasIndex(scope.TableName(), indexName) {
+	// conditions of the scope are the partial index's condition, e.g. `db.Where("deleted_at IS NULL").AddIndex(...)`
+	scope.createIndex(Index{Name: indexName, Columns: column, Unique: unique, Where: scope.indexConditionSQL()})
+}
+
+func (scope *Scope) createIndex(index Index) {
+	if scope.Dialect().HasIndex(scope.TableName(), index.Name) {
+		return
+	}
+
+	sqls, err := scope.addIndexSQL(index)
+	if scope.Err(err) != nil {
 		return
 	}
 
-	var columns []string
-	for _, name := range column {
-		columns = append(columns, scope.quoteIfPossible(name))
+	for _, sql := range sqls {
+		scope.Raw(sql).Exec()
 	}
+}
 
-	sqlCreate := "CREATE INDEX"
-	if unique {
-		sqlCreate = "CREATE UNIQUE INDEX"
+// addIndexSQL return statements creating the index
+func (scope *Scope) addIndexSQL(index Index) ([]string, error) {
+	columns := make([]string, len(index.Columns))
+	for idx, column := range index.Columns {
+		columns[idx] = scope.quoteIfPossible(column)
 	}
+	index.Columns = columns
+	return createIndexSQL(scope.Dialect(), scope.QuotedTableName(), index)
+}
 
-	scope.Raw(fmt.Sprintf("%s %v ON %v(%v) %v", sqlCreate, indexName, scope.QuotedTableName(), strings.Join(columns, ", "), scope.whereSQL())).Exec()
+// indexConditionSQL return conditions of the scope with values interpolated, as statements creating indexes can't have bind vars
+func (scope *Scope) indexConditionSQL() string {
+	conditionScope := scope.db.NewScope(scope.Value)
+	conditionScope.InstanceSet("skip_bindvar", true)
+
+	var (
+		sql        string
+		conditions = sqlRegexp.Split(strings.TrimPrefix(conditionScope.whereSQL(), "WHERE "), -1)
+	)
+	for idx, condition := range conditions {
+		if idx > 0 && idx <= len(conditionScope.SQLVars) {
+			sql += literalSQL(conditionScope.SQLVars[idx-1])
+		} else if idx > 0 {
+			sql += "?"
+		}
+		sql += condition
+	}
+	return sql
 }
 
 func (scope *Scope) addForeignKey(field string, dest string, onDelete string, onUpdate string) {
 	// Compatible with old generated key
-	keyName := scope.Dialect().BuildKeyName(scope.TableName(), field, dest, "foreign")
+	keyName := scope.relationshipFKName(scope.TableName(), field, dest)
 
 	if scope.Dialect().HasForeignKey(scope.TableName(), keyName) {
 		return
 	}
-	var query = `ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s;`
-	scope.Raw(fmt.Sprintf(query, scope.QuotedTableName(), scope.quoteIfPossible(keyName), scope.quoteIfPossible(field), dest, onDelete, onUpdate)).Exec()
+	var definition = fmt.Sprintf(`CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s ON DELETE %s ON UPDATE %s`, scope.quoteIfPossible(keyName), scope.quoteIfPossible(field), dest, onDelete, onUpdate)
+	if dialect, ok := scope.Dialect().(AddConstraintDialect); ok {
+		scope.Err(dialect.AddConstraint(scope.TableName(), definition))
+		return
+	}
+	scope.Raw(fmt.Sprintf("ALTER TABLE %s ADD %s;", scope.QuotedTableName(), definition)).Exec()
 }
 
 func (scope *Scope) removeForeignKey(field string, dest string) {
-	keyName := scope.Dialect().BuildKeyName(scope.TableName(), field, dest)
+	keyName := scope.relationshipFKName(scope.TableName(), field, dest)
 
 	if !scope.Dialect().HasForeignKey(scope.TableName(), keyName) {
 		return
@@ -1237,7 +1503,7 @@ func (scope *Scope) autoMigrate() *Scope {
 	} else {
 		for _, field := range scope.GetModelStruct().StructFields {
 			if !scope.Dialect().HasColumn(tableName, field.DBName) {
-				if field.IsNormal {
+				if field.IsNormal && !field.ignoreMigration() {
 					sqlTag := scope.Dialect().DataTypeOf(field)
 					scope.Raw(fmt.Sprintf("ALTER TABLE %v ADD %v %v;", quotedTableName, scope.Quote(field.DBName), sqlTag)).Exec()
 				}
@@ -1250,46 +1516,132 @@ func (scope *Scope) autoMigrate() *Scope {
 }
 
 func (scope *Scope) autoIndex() *Scope {
-	var indexes = map[string][]string{}
-	var uniqueIndexes = map[string][]string{}
+	for _, index := range scope.modelIndexes() {
+		indexScope := scope.NewDB().Table(scope.TableName()).Unscoped().NewScope(scope.Value)
+		indexScope.createIndex(index)
+
+		if indexScope.db.Error != nil {
+			scope.db.AddError(indexScope.db.Error)
+		}
+	}
+
+	return scope
+}
+
+// modelIndexes return indexes declared with `INDEX` and `UNIQUE_INDEX` tags, sorted by name.
+// Names of indexes are followed by options, columns of composite indexes are ordered by priority, 10 by default
+//     FirstName string     `gorm:"index:idx_name,priority:2"`
+//     LastName  string     `gorm:"index:idx_name,priority:1,sort:desc,length:10,type:btree,comment:search by name"`
+//     Email     string     `gorm:"unique_index:uix_email,where:deleted_at IS NULL"`
+//     DeletedAt *time.Time
+func (scope *Scope) modelIndexes() (results []Index) {
+	type indexColumn struct {
+		name     string
+		priority int
+		sort     string
+		length   int
+	}
+
+	var (
+		indexes = map[string]*Index{}
+		columns = map[string][]indexColumn{}
+	)
 
 	for _, field := range scope.GetStructFields() {
-		if name, ok := field.TagSettings["INDEX"]; ok {
-			names := strings.Split(name, ",")
+		if field.ignoreMigration() {
+			continue
+		}
 
-			for _, name := range names {
-				if name == "INDEX" || name == "" {
-					name = scope.Dialect().BuildKeyName("idx", scope.TableName(), field.DBName)
+		for _, kind := range []string{"INDEX", "UNIQUE_INDEX"} {
+			tag, ok := field.TagSettings[kind]
+			if !ok {
+				continue
+			}
+
+			var (
+				column      = indexColumn{name: field.DBName, priority: 10}
+				names       []string
+				options     Index
+				defaultName = scope.indexKeyName("idx", scope.TableName(), field.DBName)
+			)
+
+			if kind == "UNIQUE_INDEX" {
+				defaultName = scope.indexKeyName("uix", scope.TableName(), field.DBName)
+			}
+
+			for _, setting := range splitSQLDefinitions(tag) {
+				values := strings.SplitN(setting, ":", 2)
+				if len(values) == 1 {
+					if setting == kind || setting == "" {
+						setting = defaultName
+					}
+					names = append(names, setting)
+					continue
+				}
+
+				value := strings.TrimSpace(values[1])
+				switch strings.ToUpper(strings.TrimSpace(values[0])) {
+				case "PRIORITY":
+					if priority, err := strconv.Atoi(value); err == nil {
+						column.priority = priority
+					}
+				case "SORT":
+					column.sort = strings.ToUpper(value)
+				case "LENGTH":
+					column.length, _ = strconv.Atoi(value)
+				case "TYPE":
+					options.Type = value
+				case "WHERE":
+					options.Where = value
+				case "OPTION":
+					options.Option = value
+				case "COMMENT":
+					options.Comment = value
 				}
-				indexes[name] = append(indexes[name], field.DBName)
 			}
-		}
 
-		if name, ok := field.TagSettings["UNIQUE_INDEX"]; ok {
-			names := strings.Split(name, ",")
+			if len(names) == 0 {
+				// only options like `index:sort:desc`
+				names = append(names, defaultName)
+			}
 
 			for _, name := range names {
-				if name == "UNIQUE_INDEX" || name == "" {
-					name = scope.Dialect().BuildKeyName("uix", scope.TableName(), field.DBName)
+				index, ok := indexes[name]
+				if !ok {
+					index = &Index{Name: name}
+					indexes[name] = index
 				}
-				uniqueIndexes[name] = append(uniqueIndexes[name], field.DBName)
-			}
-		}
-	}
 
-	for name, columns := range indexes {
-		if db := scope.NewDB().Table(scope.TableName()).Model(scope.Value).AddIndex(name, columns...); db.Error != nil {
-			scope.db.AddError(db.Error)
+				index.Unique = index.Unique || kind == "UNIQUE_INDEX"
+				if options.Type != "" {
+					index.Type = options.Type
+				}
+				if options.Where != "" {
+					index.Where = options.Where
+				}
+				if options.Option != "" {
+					index.Option = options.Option
+				}
+				if options.Comment != "" {
+					index.Comment = options.Comment
+				}
+				columns[name] = append(columns[name], column)
+			}
 		}
 	}
 
-	for name, columns := range uniqueIndexes {
-		if db := scope.NewDB().Table(scope.TableName()).Model(scope.Value).AddUniqueIndex(name, columns...); db.Error != nil {
-			scope.db.AddError(db.Error)
+	for name, index := range indexes {
+		sort.SliceStable(columns[name], func(i, j int) bool { return columns[name][i].priority < columns[name][j].priority })
+		for _, column := range columns[name] {
+			index.Columns = append(index.Columns, column.name)
+			index.Sorts = append(index.Sorts, column.sort)
+			index.Lengths = append(index.Lengths, column.length)
 		}
+		results = append(results, *index)
 	}
 
-	return scope
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
 }
 
 func (scope *Scope) getColumnAsArray(columns []string, values ...interface{}) (results [][]interface{}) {
@@ -1373,6 +1725,65 @@ func (scope *Scope) getColumnAsScope(column string) *Scope {
 	return nil
 }
 
+// elemScopes return a scope for every element if scope's value is a slice or an array of structs, element scopes share current scope's db
+func (scope *Scope) elemScopes() ([]*Scope, bool) {
+	if scopes, ok := scope.InstanceGet("gorm:elem_scopes"); ok {
+		return scopes.([]*Scope), true
+	}
+
+	indirectScopeValue := scope.IndirectValue()
+	if kind := indirectScopeValue.Kind(); kind != reflect.Slice && kind != reflect.Array {
+		return nil, false
+	}
+
+	var scopes []*Scope
+	for i := 0; i < indirectScopeValue.Len(); i++ {
+		elem := indirectScopeValue.Index(i)
+		if elem.Kind() != reflect.Ptr {
+			if !elem.CanAddr() {
+				scope.Err(ErrUnaddressable)
+				return nil, true
+			}
+			elem = elem.Addr()
+		} else if elem.IsNil() {
+			continue
+		}
+
+		if indirect(elem).Kind() != reflect.Struct {
+			return nil, false
+		}
+		scopes = append(scopes, &Scope{db: scope.db, Search: scope.Search, Value: elem.Interface()})
+	}
+
+	scope.InstanceSet("gorm:elem_scopes", scopes)
+	return scopes, true
+}
+
+// batchSize return how many rows could be inserted with one statement, could be set with `gorm:batch_size`,
+// but won't exceed the dialect's bind variables and rows limit
+func (scope *Scope) batchSize(columns int) (size int) {
+	if value, ok := scope.Get("gorm:batch_size"); ok {
+		size, _ = value.(int)
+	}
+
+	if dialect, ok := scope.Dialect().(MaxBindVarsDialect); ok && columns > 0 {
+		if maxSize := dialect.MaxBindVars() / columns; size <= 0 || size > maxSize {
+			size = maxSize
+		}
+	}
+
+	if dialect, ok := scope.Dialect().(MaxInsertRowsDialect); ok {
+		if maxSize := dialect.MaxInsertRows(); size <= 0 || size > maxSize {
+			size = maxSize
+		}
+	}
+
+	if size <= 0 {
+		return int(^uint(0) >> 1)
+	}
+	return size
+}
+
 func (scope *Scope) hasConditions() bool {
 	return !scope.PrimaryKeyZero() ||
 		len(scope.Search.whereConditions) > 0 ||
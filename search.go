@@ -21,8 +21,13 @@ type search struct {
 	limit            interface{}
 	group            string
 	tableName        string
+	onConflict       *OnConflict
+	locking          *Locking
+	pagination       *pagination
+	usePrimary       bool
 	raw              bool
 	Unscoped         bool
+	OnlyDeleted      bool
 	ignoreOrderQuery bool
 }
 
@@ -123,6 +128,60 @@ func (s *search) Preload(schema string, values ...interface{}) *search {
 	return s
 }
 
+func (s *search) OnConflict(columns ...string) *search {
+	s.onConflict = &OnConflict{Columns: columns}
+	return s
+}
+
+func (s *search) DoUpdate(columns ...string) *search {
+	onConflict := s.copyOnConflict()
+	onConflict.DoUpdates = append(onConflict.DoUpdates, columns...)
+	return s
+}
+
+func (s *search) DoNothing() *search {
+	s.copyOnConflict().DoNothing = true
+	return s
+}
+
+func (s *search) UpdateAll() *search {
+	s.copyOnConflict().UpdateAll = true
+	return s
+}
+
+// copyOnConflict copy current OnConflict before changing it, as it is shared with the search it cloned from
+func (s *search) copyOnConflict() *OnConflict {
+	onConflict := OnConflict{}
+	if s.onConflict != nil {
+		onConflict = *s.onConflict
+		onConflict.DoUpdates = append([]string{}, s.onConflict.DoUpdates...)
+	}
+	s.onConflict = &onConflict
+	return s.onConflict
+}
+
+func (s *search) Clauses(clauses ...interface{}) *search {
+	for _, clause := range clauses {
+		switch clause := clause.(type) {
+		case Locking:
+			s.locking = &clause
+		case *Locking:
+			locking := *clause
+			s.locking = &locking
+		case usePrimaryClause:
+			s.usePrimary = true
+		default:
+			s.db.AddError(fmt.Errorf("unsupported clause: %T", clause))
+		}
+	}
+	return s
+}
+
+func (s *search) Paginate(cursor string, limit int, orderColumns ...string) *search {
+	s.pagination = &pagination{cursor: cursor, limit: limit, orderColumns: orderColumns}
+	return s
+}
+
 func (s *search) Raw(b bool) *search {
 	s.raw = b
 	return s
@@ -133,6 +192,11 @@ func (s *search) unscoped() *search {
 	return s
 }
 
+func (s *search) onlyDeleted() *search {
+	s.OnlyDeleted = true
+	return s
+}
+
 func (s *search) Table(name string) *search {
 	s.tableName = name
 	return s
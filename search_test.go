@@ -28,3 +28,19 @@ func TestCloneSearch(t *testing.T) {
 		t.Errorf("selectStr should be copied")
 	}
 }
+
+func TestCloneSearchOnConflict(t *testing.T) {
+	s := new(search)
+	s.OnConflict("email").DoUpdate("name")
+
+	s1 := s.clone()
+	s1.DoUpdate("age").DoNothing()
+
+	if !reflect.DeepEqual(s.onConflict, &OnConflict{Columns: []string{"email"}, DoUpdates: []string{"name"}}) {
+		t.Errorf("OnConflict should not be changed by cloned search, but got %+v", s.onConflict)
+	}
+
+	if !reflect.DeepEqual(s1.onConflict, &OnConflict{Columns: []string{"email"}, DoUpdates: []string{"name", "age"}, DoNothing: true}) {
+		t.Errorf("OnConflict should be copied, but got %+v", s1.onConflict)
+	}
+}
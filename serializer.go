@@ -0,0 +1,214 @@
+package gorm
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Serializer encode field values to database values and decode them back, it is used by fields tagged with `serializer:name`
+//     type User struct {
+//       Attrs map[string]string `gorm:"serializer:json"`
+//     }
+type Serializer interface {
+	// Scan decode the database value and set it to the field
+	Scan(field *Field, dbValue interface{}) error
+	// Value encode the field value to a database value
+	Value(field *Field, fieldValue interface{}) (interface{}, error)
+}
+
+var serializers = struct {
+	sync.RWMutex
+	m map[string]Serializer
+}{m: map[string]Serializer{
+	"json":     JSONSerializer{},
+	"gob":      GobSerializer{},
+	"unixtime": UnixTimeSerializer{},
+}}
+
+// RegisterSerializer register a serializer with the name used in `serializer` tags
+func RegisterSerializer(name string, serializer Serializer) {
+	serializers.Lock()
+	defer serializers.Unlock()
+	serializers.m[strings.ToLower(name)] = serializer
+}
+
+// serializer return the serializer of the field, nil if it is not tagged with `serializer`
+func (field *StructField) serializer() (Serializer, error) {
+	name, ok := field.TagSettings["SERIALIZER"]
+	if !ok {
+		return nil, nil
+	}
+
+	serializers.RLock()
+	defer serializers.RUnlock()
+	if serializer, ok := serializers.m[strings.ToLower(name)]; ok {
+		return serializer, nil
+	}
+	return nil, fmt.Errorf("unregistered serializer %v of field %v", name, field.Name)
+}
+
+func isNilValue(value interface{}) bool {
+	reflectValue := reflect.ValueOf(value)
+	switch reflectValue.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return reflectValue.IsNil()
+	}
+	return false
+}
+
+func bytesOfDBValue(field *Field, dbValue interface{}) ([]byte, error) {
+	switch value := dbValue.(type) {
+	case []byte:
+		return value, nil
+	case string:
+		return []byte(value), nil
+	}
+	return nil, fmt.Errorf("failed to decode value %#v of field %v", dbValue, field.Name)
+}
+
+// JSONSerializer store field values as JSON, the column type is `jsonb` for postgres, `json` for mysql, text for others
+type JSONSerializer struct{}
+
+// Scan decode JSON to the field
+func (JSONSerializer) Scan(field *Field, dbValue interface{}) error {
+	if dbValue == nil {
+		return field.Set(nil)
+	}
+
+	data, err := bytesOfDBValue(field, dbValue)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.New(field.Struct.Type)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, value.Interface()); err != nil {
+			return err
+		}
+	}
+	return field.Set(value.Elem())
+}
+
+// Value encode the field value to JSON
+func (JSONSerializer) Value(field *Field, fieldValue interface{}) (interface{}, error) {
+	if isNilValue(fieldValue) {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(fieldValue)
+	return string(data), err
+}
+
+// GormDataType column type of JSON values
+func (JSONSerializer) GormDataType(dialect Dialect) string {
+	switch dialect.GetName() {
+	case "postgres":
+		return "jsonb"
+	case "mysql":
+		return "json"
+	case "mssql":
+		return "nvarchar(max)"
+	}
+	return "text"
+}
+
+// GobSerializer store field values encoded with gob
+type GobSerializer struct{}
+
+// Scan decode gob data to the field
+func (GobSerializer) Scan(field *Field, dbValue interface{}) error {
+	if dbValue == nil {
+		return field.Set(nil)
+	}
+
+	data, err := bytesOfDBValue(field, dbValue)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.New(field.Struct.Type)
+	if len(data) > 0 {
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(value.Interface()); err != nil {
+			return err
+		}
+	}
+	return field.Set(value.Elem())
+}
+
+// Value encode the field value with gob
+func (GobSerializer) Value(field *Field, fieldValue interface{}) (interface{}, error) {
+	if isNilValue(fieldValue) {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(fieldValue)
+	return buf.Bytes(), err
+}
+
+// GormDataType column type of gob data
+func (GobSerializer) GormDataType(dialect Dialect) string {
+	switch dialect.GetName() {
+	case "postgres":
+		return "bytea"
+	case "mysql":
+		return "longblob"
+	case "mssql":
+		return "varbinary(max)"
+	}
+	return "blob"
+}
+
+// UnixTimeSerializer store `time.Time` fields as unix seconds
+type UnixTimeSerializer struct{}
+
+// Scan convert unix seconds to time
+func (UnixTimeSerializer) Scan(field *Field, dbValue interface{}) error {
+	var seconds int64
+	switch value := dbValue.(type) {
+	case nil:
+		return field.Set(nil)
+	case int64:
+		seconds = value
+	case []byte, string:
+		data, _ := bytesOfDBValue(field, value)
+		parsed, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return err
+		}
+		seconds = parsed
+	default:
+		return fmt.Errorf("failed to decode value %#v of field %v", dbValue, field.Name)
+	}
+	return field.Set(time.Unix(seconds, 0))
+}
+
+// Value convert time to unix seconds
+func (UnixTimeSerializer) Value(field *Field, fieldValue interface{}) (interface{}, error) {
+	switch value := fieldValue.(type) {
+	case time.Time:
+		return value.Unix(), nil
+	case *time.Time:
+		if value == nil {
+			return nil, nil
+		}
+		return value.Unix(), nil
+	case nil:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("invalid value %#v of field %v, should be time", fieldValue, field.Name)
+}
+
+// GormDataType column type of unix seconds
+func (UnixTimeSerializer) GormDataType(dialect Dialect) string {
+	return "bigint"
+}
@@ -0,0 +1,125 @@
+package gorm_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type SerializerContact struct {
+	Email string
+	Phone string
+}
+
+type SerializerStruct struct {
+	ID        uint
+	Name      string
+	Roles     []string           `gorm:"serializer:json"`
+	Attrs     map[string]string  `gorm:"serializer:json"`
+	Contact   SerializerContact  `gorm:"serializer:json"`
+	Backup    *SerializerContact `gorm:"serializer:json"`
+	Settings  SerializerContact  `gorm:"serializer:gob"`
+	LoggedAt  time.Time          `gorm:"serializer:unixtime"`
+	Encrypted string             `gorm:"serializer:reverse"`
+}
+
+type reverseSerializer struct{}
+
+func (reverseSerializer) Scan(field *gorm.Field, dbValue interface{}) error {
+	value, ok := dbValue.(string)
+	if !ok {
+		value = string(dbValue.([]byte))
+	}
+	return field.Set(reverseString(value))
+}
+
+func (reverseSerializer) Value(field *gorm.Field, fieldValue interface{}) (interface{}, error) {
+	return reverseString(fmt.Sprint(fieldValue)), nil
+}
+
+func reverseString(str string) string {
+	runes := []rune(str)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func init() {
+	gorm.RegisterSerializer("reverse", reverseSerializer{})
+}
+
+func TestSerializer(t *testing.T) {
+	DB.DropTableIfExists(&SerializerStruct{})
+	if err := DB.AutoMigrate(&SerializerStruct{}).Error; err != nil {
+		t.Fatalf("no error should happen when migrating, but got %v", err)
+	}
+
+	loggedAt := time.Unix(time.Now().Unix(), 0)
+	data := SerializerStruct{
+		Name:      "serializer",
+		Roles:     []string{"admin", "owner"},
+		Attrs:     map[string]string{"locale": "en"},
+		Contact:   SerializerContact{Email: "serializer@example.com"},
+		Settings:  SerializerContact{Phone: "123456"},
+		LoggedAt:  loggedAt,
+		Encrypted: "secret",
+	}
+
+	if err := DB.Create(&data).Error; err != nil {
+		t.Fatalf("no error should happen when creating, but got %v", err)
+	}
+
+	var result SerializerStruct
+	if err := DB.First(&result, data.ID).Error; err != nil {
+		t.Fatalf("no error should happen when querying, but got %v", err)
+	}
+
+	if !reflect.DeepEqual(result.Roles, data.Roles) || !reflect.DeepEqual(result.Attrs, data.Attrs) ||
+		result.Contact != data.Contact || result.Backup != nil || result.Settings != data.Settings ||
+		!result.LoggedAt.Equal(loggedAt) || result.Encrypted != "secret" {
+		t.Errorf("serialized fields should be decoded, but got %#v", result)
+	}
+
+	var (
+		roles     string
+		loggedAtU int64
+		encrypted string
+	)
+	DB.Model(&SerializerStruct{}).Where("id = ?", data.ID).Select("roles, logged_at, encrypted").Row().Scan(&roles, &loggedAtU, &encrypted)
+	if roles != `["admin","owner"]` || loggedAtU != loggedAt.Unix() || encrypted != "terces" {
+		t.Errorf("serialized fields should be encoded, but got %v, %v, %v", roles, loggedAtU, encrypted)
+	}
+
+	DB.Model(&result).Updates(map[string]interface{}{"roles": []string{"guest"}, "backup": &SerializerContact{Email: "backup@example.com"}})
+
+	var updated SerializerStruct
+	DB.First(&updated, data.ID)
+	if !reflect.DeepEqual(updated.Roles, []string{"guest"}) || updated.Backup == nil || updated.Backup.Email != "backup@example.com" {
+		t.Errorf("serialized fields should be updated, but got %#v", updated)
+	}
+
+	var found SerializerStruct
+	if err := DB.Where(&SerializerStruct{Encrypted: "secret"}).First(&found).Error; err != nil || found.ID != data.ID {
+		t.Errorf("struct conditions should be encoded with serializer, but got %v", err)
+	}
+}
+
+func TestSerializerDataType(t *testing.T) {
+	scope := DB.NewScope(&SerializerStruct{})
+	attrs, _ := scope.FieldByName("Attrs")
+	loggedAt, _ := scope.FieldByName("LoggedAt")
+
+	if dataType := strings.ToLower(DB.Dialect().DataTypeOf(loggedAt.StructField)); dataType != "bigint" {
+		t.Errorf("unix time should be stored as bigint, but got %v", dataType)
+	}
+
+	expected := map[string]string{"postgres": "jsonb", "mysql": "json", "mssql": "nvarchar(max)", "sqlite3": "text"}[DB.Dialect().GetName()]
+	if dataType := strings.ToLower(DB.Dialect().DataTypeOf(attrs.StructField)); dataType != expected {
+		t.Errorf("json should be stored as %v, but got %v", expected, dataType)
+	}
+}
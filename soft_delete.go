@@ -0,0 +1,196 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// SoftDeleter decide how a field marks records as deleted, the field is declared with the `softDelete` tag or its type implements `SoftDeleter`,
+// a field named `DeletedAt` is a nullable timestamp by default
+//     type User struct {
+//       ID        uint
+//       DeletedAt int64  `gorm:"softDelete:unix"`
+//       DeletedBy string `gorm:"deletedBy"`
+//     }
+type SoftDeleter interface {
+	// DeletedValue return the value of the field set when deleting records
+	DeletedValue(scope *Scope) interface{}
+	// NotDeletedValue return the value of the field of records not deleted, nil means NULL
+	NotDeletedValue() interface{}
+}
+
+var softDeleters = struct {
+	sync.RWMutex
+	m map[string]SoftDeleter
+}{m: map[string]SoftDeleter{
+	"time": TimeSoftDelete{},
+	"unix": UnixSoftDelete{},
+	"flag": FlagSoftDelete{},
+}}
+
+// RegisterSoftDeleter register a soft deleter with the name used in `softDelete` tags
+func RegisterSoftDeleter(name string, softDeleter SoftDeleter) {
+	softDeleters.Lock()
+	defer softDeleters.Unlock()
+	softDeleters.m[strings.ToLower(name)] = softDeleter
+}
+
+// TimeSoftDelete set the field to current time when deleting, records not deleted are NULL
+type TimeSoftDelete struct{}
+
+// DeletedValue return current time
+func (TimeSoftDelete) DeletedValue(scope *Scope) interface{} {
+	return scope.db.now()
+}
+
+// NotDeletedValue return nil
+func (TimeSoftDelete) NotDeletedValue() interface{} {
+	return nil
+}
+
+// UnixSoftDelete set the field to unix seconds when deleting, records not deleted are 0, so unique indexes including the field work
+type UnixSoftDelete struct{}
+
+// DeletedValue return current unix seconds
+func (UnixSoftDelete) DeletedValue(scope *Scope) interface{} {
+	return scope.db.now().Unix()
+}
+
+// NotDeletedValue return 0
+func (UnixSoftDelete) NotDeletedValue() interface{} {
+	return 0
+}
+
+// FlagSoftDelete set the boolean field to true when deleting
+type FlagSoftDelete struct{}
+
+// DeletedValue return true
+func (FlagSoftDelete) DeletedValue(scope *Scope) interface{} {
+	return true
+}
+
+// NotDeletedValue return false
+func (FlagSoftDelete) NotDeletedValue() interface{} {
+	return false
+}
+
+// softDeleteField return the soft delete field of the model and its soft deleter, nil if the model isn't soft deleted
+func (scope *Scope) softDeleteField() (*StructField, SoftDeleter) {
+	var deletedAtField *StructField
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.IsIgnored {
+			continue
+		}
+
+		if name, ok := field.TagSettings["SOFTDELETE"]; ok {
+			if name == "SOFTDELETE" {
+				name = defaultSoftDeleterName(field.Struct.Type)
+			}
+
+			softDeleters.RLock()
+			softDeleter, ok := softDeleters.m[strings.ToLower(strings.TrimSpace(name))]
+			softDeleters.RUnlock()
+			if !ok {
+				scope.Err(fmt.Errorf("unregistered soft deleter %v of field %v", name, field.Name))
+				return nil, nil
+			}
+			return field, softDeleter
+		}
+
+		if softDeleter, ok := reflect.Zero(field.Struct.Type).Interface().(SoftDeleter); ok {
+			return field, softDeleter
+		}
+
+		if field.Name == "DeletedAt" && deletedAtField == nil {
+			deletedAtField = field
+		}
+	}
+
+	if deletedAtField != nil {
+		return deletedAtField, TimeSoftDelete{}
+	}
+	return nil, nil
+}
+
+// defaultSoftDeleterName return the soft deleter of fields tagged with `softDelete` without a name, decided by its type
+func defaultSoftDeleterName(fieldType reflect.Type) string {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return "flag"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "unix"
+	}
+	return "time"
+}
+
+// deletedByField return the field tagged with `deletedBy`, which is set to the value of `gorm:deleted_by` when soft deleting
+func (scope *Scope) deletedByField() *StructField {
+	for _, field := range scope.GetModelStruct().StructFields {
+		if _, ok := field.TagSettings["DELETEDBY"]; ok && !field.IsIgnored {
+			return field
+		}
+	}
+	return nil
+}
+
+// softDeleteSQL return the condition of records not deleted, or deleted ones if deleted is true
+func (scope *Scope) softDeleteSQL(field *StructField, softDeleter SoftDeleter, deleted bool) string {
+	quotedColumn := fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(field.DBName))
+	notDeletedValue := softDeleter.NotDeletedValue()
+
+	if notDeletedValue == nil {
+		if deleted {
+			return fmt.Sprintf("%v IS NOT NULL", quotedColumn)
+		}
+		return fmt.Sprintf("%v IS NULL", quotedColumn)
+	}
+
+	if deleted {
+		return fmt.Sprintf("%v <> %v", quotedColumn, scope.AddToVars(notDeletedValue))
+	}
+	return fmt.Sprintf("%v = %v", quotedColumn, scope.AddToVars(notDeletedValue))
+}
+
+// OnlyDeleted query soft deleted records only
+//     db.OnlyDeleted().Find(&users)
+func (s *DB) OnlyDeleted() *DB {
+	return s.clone().search.onlyDeleted().db
+}
+
+// Restore undelete soft deleted records, the soft delete field is set to its not deleted value and the `deletedBy` field is cleared,
+// ErrMissingWhereClause is returned without primary keys or conditions, restore all records with conditions like `Where("1 = 1")`
+//     db.Restore(&user)
+//     db.Unscoped().Model(&User{}).Where("name = ?", "jinzhu").Restore()
+func (s *DB) Restore(values ...interface{}) *DB {
+	value := s.Value
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	scope := s.NewScope(value)
+	field, softDeleter := scope.softDeleteField()
+	if field == nil {
+		if !scope.HasError() {
+			scope.Err(errors.New("model isn't soft deleted"))
+		}
+		return scope.db
+	} else if !scope.hasConditions() {
+		scope.Err(ErrMissingWhereClause)
+		return scope.db
+	}
+
+	restoredValues := map[string]interface{}{field.DBName: softDeleter.NotDeletedValue()}
+	if deletedByField := scope.deletedByField(); deletedByField != nil {
+		restoredValues[deletedByField.DBName] = reflect.Zero(deletedByField.Struct.Type).Interface()
+	}
+
+	return s.OnlyDeleted().Model(value).UpdateColumns(restoredValues)
+}
@@ -463,3 +463,94 @@ func TestUpdateDecodeVirtualAttributes(t *testing.T) {
 		t.Errorf("should decode virtual attributes to struct, so it could be used in callbacks")
 	}
 }
+
+type VersionedProduct struct {
+	ID      uint
+	Name    string
+	Price   int
+	Version gorm.Version
+}
+
+type TaggedVersionProduct struct {
+	ID       uint
+	Name     string
+	Revision uint `gorm:"version"`
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	DB.DropTableIfExists(&VersionedProduct{})
+	DB.AutoMigrate(&VersionedProduct{})
+
+	product := VersionedProduct{Name: "product", Price: 10}
+	DB.Save(&product)
+
+	var stale VersionedProduct
+	DB.First(&stale, product.ID)
+
+	if err := DB.Model(&product).Update("name", "product2").Error; err != nil {
+		t.Errorf("should update product with current version, but got %v", err)
+	}
+
+	if product.Version != 1 {
+		t.Errorf("version should be written back after update, but got %v", product.Version)
+	}
+
+	if err := DB.Model(&stale).Updates(map[string]interface{}{"price": 20}).Error; err != gorm.ErrStaleObject {
+		t.Errorf("should return ErrStaleObject when updating stale object, but got %v", err)
+	}
+
+	stale.Name = "stale"
+	if err := DB.Save(&stale).Error; err != gorm.ErrStaleObject {
+		t.Errorf("should return ErrStaleObject when saving stale object, but got %v", err)
+	}
+
+	if err := DB.Model(&stale).UpdateColumn("price", 30).Error; err != gorm.ErrStaleObject {
+		t.Errorf("should return ErrStaleObject when updating column of stale object, but got %v", err)
+	}
+
+	product.Price = 40
+	if err := DB.Save(&product).Error; err != nil || product.Version != 2 {
+		t.Errorf("should save product with current version, but got %v, version %v", err, product.Version)
+	}
+
+	var result VersionedProduct
+	DB.First(&result, product.ID)
+	if result.Name != "product2" || result.Price != 40 || result.Version != 2 {
+		t.Errorf("stale updates should not be saved, but got %#v", result)
+	}
+
+	if err := DB.Set("gorm:skip_version_check", true).Model(&stale).UpdateColumn("price", 50).Error; err != nil {
+		t.Errorf("should update column without version check, but got %v", err)
+	}
+
+	DB.First(&result, product.ID)
+	if result.Price != 50 || result.Version != 2 {
+		t.Errorf("update column without version check should not change version, but got %#v", result)
+	}
+
+	if err := DB.Model(&VersionedProduct{}).Where("id = ?", product.ID).Update("price", 60).Error; err != nil {
+		t.Errorf("should update without primary key, but got %v", err)
+	}
+
+	DB.First(&result, product.ID)
+	if result.Price != 60 || result.Version != 3 {
+		t.Errorf("update without primary key should only increase version, but got %#v", result)
+	}
+}
+
+func TestOptimisticLockingWithTag(t *testing.T) {
+	DB.DropTableIfExists(&TaggedVersionProduct{})
+	DB.AutoMigrate(&TaggedVersionProduct{})
+
+	product := TaggedVersionProduct{Name: "product"}
+	DB.Save(&product)
+	stale := product
+
+	if err := DB.Model(&product).Updates(TaggedVersionProduct{Name: "product2"}).Error; err != nil || product.Revision != 1 {
+		t.Errorf("should update product with tagged version, but got %v, revision %v", err, product.Revision)
+	}
+
+	if err := DB.Model(&stale).Updates(TaggedVersionProduct{Name: "stale"}).Error; err != gorm.ErrStaleObject {
+		t.Errorf("should return ErrStaleObject when updating stale object, but got %v", err)
+	}
+}